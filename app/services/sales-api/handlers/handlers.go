@@ -2,83 +2,69 @@
 package handlers
 
 import (
-	"context"
-	"net/http"
+	"compress/gzip"
 	"os"
+	"time"
 
 	v1 "github.com/ardanlabs/service/app/services/sales-api/handlers/v1"
+	"github.com/ardanlabs/service/business/sys/database"
 	"github.com/ardanlabs/service/business/web/auth"
+	"github.com/ardanlabs/service/business/web/ratelimit"
 	"github.com/ardanlabs/service/business/web/v1/mid"
 	"github.com/ardanlabs/service/foundation/web"
-	"github.com/jmoiron/sqlx"
+	"github.com/klauspost/compress/zstd"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
-// Options represent optional parameters.
-type Options struct {
-	corsOrigin string
-}
-
-// WithCORS provides configuration options for CORS.
-func WithCORS(origin string) func(opts *Options) {
-	return func(opts *Options) {
-		opts.corsOrigin = origin
-	}
-}
-
 // APIMuxConfig contains all the mandatory systems required by handlers.
 type APIMuxConfig struct {
-	Build    string
-	Shutdown chan os.Signal
-	Log      *zap.SugaredLogger
-	Auth     *auth.Auth
-	DB       *sqlx.DB
-	Tracer   trace.Tracer
+	Build                  string
+	Shutdown               chan os.Signal
+	Log                    *zap.SugaredLogger
+	Auth                   *auth.Auth
+	DB                     *database.DB
+	Tracer                 trace.Tracer
+	QueryTimeout           time.Duration
+	Limiter                *ratelimit.Limiter
+	PublicRate             ratelimit.Rate
+	UserRate               ratelimit.Rate
+	TrustedProxies         mid.TrustedProxies
+	IdempotencyTTL         time.Duration
+	IdempotencyLockTimeout time.Duration
+	CORS                   mid.CORSPolicy
+	PublicCORS             mid.CORSPolicy
+	HSTSMaxAge             time.Duration
 }
 
-// APIMux constructs a http.Handler with all application routes defined.
-func APIMux(cfg APIMuxConfig, options ...func(opts *Options)) http.Handler {
-	var opts Options
-	for _, option := range options {
-		option(&opts)
-	}
-
-	var app *web.App
-
-	if opts.corsOrigin != "" {
-		app = web.NewApp(
-			cfg.Shutdown,
-			cfg.Tracer,
-			mid.Logger(cfg.Log),
-			mid.Errors(cfg.Log),
-			mid.Metrics(),
-			mid.Cors(opts.corsOrigin),
-			mid.Panics(),
-		)
-
-		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-			return nil
-		}
-		app.Handle(http.MethodOptions, "", "/*", h, mid.Cors(opts.corsOrigin))
-	}
-
-	if app == nil {
-		app = web.NewApp(
-			cfg.Shutdown,
-			cfg.Tracer,
-			mid.Logger(cfg.Log),
-			mid.Errors(cfg.Log),
-			mid.Metrics(),
-			mid.Panics(),
-		)
-	}
+// APIMux constructs a web.App with all application routes defined.
+func APIMux(cfg APIMuxConfig) *web.App {
+	app := web.NewApp(
+		cfg.Shutdown,
+		cfg.Tracer,
+		web.Compress(web.Zstd(zstd.SpeedDefault), web.Gzip(gzip.DefaultCompression)),
+		mid.SecurityHeaders(cfg.HSTSMaxAge),
+		mid.Logger(cfg.Log),
+		mid.Metrics(),
+		mid.Errors(cfg.Log),
+		mid.Panics(),
+		mid.ReadYourWrites(),
+	)
 
 	v1.Routes(app, v1.Config{
-		Build: cfg.Build,
-		Log:   cfg.Log,
-		Auth:  cfg.Auth,
-		DB:    cfg.DB,
+		Build:                  cfg.Build,
+		Log:                    cfg.Log,
+		Auth:                   cfg.Auth,
+		DB:                     cfg.DB,
+		QueryTimeout:           cfg.QueryTimeout,
+		Limiter:                cfg.Limiter,
+		PublicRate:             cfg.PublicRate,
+		UserRate:               cfg.UserRate,
+		TrustedProxies:         cfg.TrustedProxies,
+		IdempotencyTTL:         cfg.IdempotencyTTL,
+		IdempotencyLockTimeout: cfg.IdempotencyLockTimeout,
+		CORS:                   cfg.CORS,
+		PublicCORS:             cfg.PublicCORS,
 	})
 
 	return app
@@ -7,19 +7,18 @@ import (
 	"os"
 	"time"
 
-	database "github.com/ardanlabs/service/business/sys/database/pgx"
+	"github.com/ardanlabs/service/business/sys/database"
 	"github.com/ardanlabs/service/foundation/web"
-	"github.com/jmoiron/sqlx"
 )
 
 // Handlers manages the set of check endpoints.
 type Handlers struct {
 	build string
-	db    *sqlx.DB
+	db    *database.DB
 }
 
 // New constructs a Handlers api for the check group.
-func New(build string, db *sqlx.DB) *Handlers {
+func New(build string, db *database.DB) *Handlers {
 	return &Handlers{
 		build: build,
 		db:    db,
@@ -38,14 +37,12 @@ func (h *Handlers) Readiness(ctx context.Context, w http.ResponseWriter, r *http
 
 	status := "ok"
 	statusCode := http.StatusOK
-	if err := database.StatusCheck(ctx, h.db); err != nil {
+	if err := database.StatusCheck(ctx, h.db.DB); err != nil {
 		status = "db not ready"
 		statusCode = http.StatusInternalServerError
 	}
 
-	data := struct {
-		Status string `json:"status"`
-	}{
+	data := AppReadiness{
 		Status: status,
 	}
 
@@ -62,16 +59,7 @@ func (h *Handlers) Liveness(ctx context.Context, w http.ResponseWriter, r *http.
 		host = "unavailable"
 	}
 
-	data := struct {
-		Status     string `json:"status,omitempty"`
-		Build      string `json:"build,omitempty"`
-		Host       string `json:"host,omitempty"`
-		Name       string `json:"name,omitempty"`
-		PodIP      string `json:"podIP,omitempty"`
-		Node       string `json:"node,omitempty"`
-		Namespace  string `json:"namespace,omitempty"`
-		GOMAXPROCS string `json:"GOMAXPROCS,omitempty"`
-	}{
+	data := AppLiveness{
 		Status:     "up",
 		Build:      h.build,
 		Host:       host,
@@ -0,0 +1,19 @@
+package checkgrp
+
+// AppReadiness represents the readiness status of the service.
+type AppReadiness struct {
+	Status string `json:"status"`
+}
+
+// AppLiveness represents the liveness status of the service along with
+// details about where it is running.
+type AppLiveness struct {
+	Status     string `json:"status,omitempty"`
+	Build      string `json:"build,omitempty"`
+	Host       string `json:"host,omitempty"`
+	Name       string `json:"name,omitempty"`
+	PodIP      string `json:"podIP,omitempty"`
+	Node       string `json:"node,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	GOMAXPROCS string `json:"GOMAXPROCS,omitempty"`
+}
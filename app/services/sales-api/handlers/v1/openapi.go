@@ -0,0 +1,233 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/services/sales-api/handlers/v1/checkgrp"
+	"github.com/ardanlabs/service/app/services/sales-api/handlers/v1/productgrp"
+	"github.com/ardanlabs/service/app/services/sales-api/handlers/v1/reportgrp"
+	"github.com/ardanlabs/service/app/services/sales-api/handlers/v1/usergrp"
+	"github.com/ardanlabs/service/business/web/idempotency"
+	v1 "github.com/ardanlabs/service/business/web/v1"
+	"github.com/ardanlabs/service/business/web/v1/paging"
+	"github.com/ardanlabs/service/foundation/openapi"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// Document generates the OpenAPI document for the routes bound to the app.
+func Document(app *web.App, build string) (openapi.Document, error) {
+	return openapi.Generate(openAPIConfig(build), app.Routes(), endpoints())
+}
+
+// openAPIConfig returns the configuration describing the API as a whole.
+func openAPIConfig(build string) openapi.Config {
+	return openapi.Config{
+		Title:          "Sales API",
+		Version:        build,
+		ErrorResponse:  v1.ErrorResponse{},
+		AuthMiddleware: "mid.Authenticate",
+	}
+}
+
+// endpoints documents the contract of every route in the version 1 api. A
+// route that is not documented here will fail to generate a document.
+func endpoints() []openapi.Endpoint {
+	const (
+		tagCheck   = "check"
+		tagUser    = "users"
+		tagProduct = "products"
+		tagReport  = "reports"
+		tagDocs    = "docs"
+	)
+
+	pageParams := []openapi.Parameter{
+		openapi.QueryParam("page", "integer", "int64"),
+		openapi.QueryParam("rows", "integer", "int64"),
+		openapi.QueryParam("orderBy", "string", ""),
+	}
+
+	idempotencyKey := openapi.HeaderParam("Idempotency-Key", "string", "")
+	maxKeyLength := idempotency.MaxKeyLength
+	idempotencyKey.Schema.MaxLength = &maxKeyLength
+
+	userParams := params(usergrp.FilterParams, pageParams)
+	summaryParams := params(usergrp.SummaryFilterParams, pageParams)
+	productParams := params(productgrp.FilterParams, productgrp.FieldParams, pageParams)
+
+	activityParams := []openapi.Parameter{
+		openapi.QueryParam("interval", "string", ""),
+		openapi.QueryParam("user_id", "string", "uuid"),
+		openapi.QueryParam("start_date", "string", "date-time"),
+		openapi.QueryParam("end_date", "string", "date-time"),
+	}
+
+	inventoryParams := params([]openapi.Parameter{
+		openapi.QueryParam("user_id", "string", "uuid"),
+		openapi.QueryParam("department", "string", ""),
+		openapi.QueryParam("include_empty", "boolean", ""),
+	}, pageParams)
+
+	userID := openapi.PathParam("user_id", "string", "uuid")
+	productID := openapi.PathParam("product_id", "string", "uuid")
+
+	return []openapi.Endpoint{
+		{
+			Method:   http.MethodGet,
+			Path:     "/" + version + "/readiness",
+			Summary:  "Reports if the service is ready to receive traffic.",
+			Tags:     []string{tagCheck},
+			Response: checkgrp.AppReadiness{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/" + version + "/liveness",
+			Summary:  "Reports if the service is alive.",
+			Tags:     []string{tagCheck},
+			Response: checkgrp.AppLiveness{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/" + version + "/openapi.json",
+			Summary:  "Returns this document.",
+			Tags:     []string{tagDocs},
+			Response: map[string]any{},
+		},
+
+		// ---------------------------------------------------------------------
+
+		{
+			Method:   http.MethodGet,
+			Path:     "/" + version + "/users/token/:kid",
+			Summary:  "Generates a token for the user provided in Basic auth.",
+			Tags:     []string{tagUser},
+			Response: usergrp.AppToken{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/" + version + "/users",
+			Summary:  "Returns a page of users.",
+			Tags:     []string{tagUser},
+			Params:   userParams,
+			Response: paging.Response[usergrp.AppUser]{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/" + version + "/users/summary",
+			Summary:  "Returns a page of user product summaries.",
+			Tags:     []string{tagUser},
+			Params:   summaryParams,
+			Response: paging.Response[usergrp.AppSummary]{},
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/" + version + "/users",
+			Summary:  "Creates a user.",
+			Tags:     []string{tagUser},
+			Params:   []openapi.Parameter{idempotencyKey},
+			Request:  usergrp.AppNewUser{},
+			Status:   http.StatusCreated,
+			Response: usergrp.AppUser{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/" + version + "/users/:user_id",
+			Summary:  "Returns the specified user.",
+			Tags:     []string{tagUser},
+			Params:   []openapi.Parameter{userID},
+			Response: usergrp.AppUser{},
+		},
+		{
+			Method:   http.MethodPut,
+			Path:     "/" + version + "/users/:user_id",
+			Summary:  "Updates the specified user.",
+			Tags:     []string{tagUser},
+			Params:   []openapi.Parameter{userID},
+			Request:  usergrp.AppUpdateUser{},
+			Response: usergrp.AppUser{},
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/" + version + "/users/:user_id",
+			Summary: "Deletes the specified user.",
+			Tags:    []string{tagUser},
+			Params:  []openapi.Parameter{userID},
+			Status:  http.StatusNoContent,
+		},
+
+		// ---------------------------------------------------------------------
+
+		{
+			Method:   http.MethodGet,
+			Path:     "/" + version + "/products",
+			Summary:  "Returns a page of products with the name of their owner.",
+			Tags:     []string{tagProduct},
+			Params:   productParams,
+			Response: paging.Response[productgrp.AppProductDetails]{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/" + version + "/products/:product_id",
+			Summary:  "Returns the specified product.",
+			Tags:     []string{tagProduct},
+			Params:   []openapi.Parameter{productID},
+			Response: productgrp.AppProduct{},
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/" + version + "/products",
+			Summary:  "Creates a product.",
+			Tags:     []string{tagProduct},
+			Params:   []openapi.Parameter{idempotencyKey},
+			Request:  productgrp.AppNewProduct{},
+			Status:   http.StatusCreated,
+			Response: productgrp.AppProduct{},
+		},
+		{
+			Method:   http.MethodPut,
+			Path:     "/" + version + "/products/:product_id",
+			Summary:  "Updates the specified product.",
+			Tags:     []string{tagProduct},
+			Params:   []openapi.Parameter{productID},
+			Request:  productgrp.AppUpdateProduct{},
+			Response: productgrp.AppProduct{},
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/" + version + "/products/:product_id",
+			Summary: "Deletes the specified product.",
+			Tags:    []string{tagProduct},
+			Params:  []openapi.Parameter{productID},
+			Status:  http.StatusNoContent,
+		},
+
+		// ---------------------------------------------------------------------
+
+		{
+			Method:   http.MethodGet,
+			Path:     "/" + version + "/reports/activity",
+			Summary:  "Returns the products created per day, week or month.",
+			Tags:     []string{tagReport},
+			Params:   activityParams,
+			Response: []reportgrp.AppActivity{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/" + version + "/reports/inventory",
+			Summary:  "Returns a page of the value of the products held by each user.",
+			Tags:     []string{tagReport},
+			Params:   inventoryParams,
+			Response: paging.Response[reportgrp.AppInventory]{},
+		},
+	}
+}
+
+// params joins lists of parameters into a new list, so the lists shared
+// between operations are never modified.
+func params(lists ...[]openapi.Parameter) []openapi.Parameter {
+	var all []openapi.Parameter
+	for _, list := range lists {
+		all = append(all, list...)
+	}
+
+	return all
+}
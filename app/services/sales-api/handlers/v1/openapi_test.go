@@ -0,0 +1,184 @@
+package v1_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/app/services/sales-api/handlers"
+	handlersv1 "github.com/ardanlabs/service/app/services/sales-api/handlers/v1"
+	"github.com/ardanlabs/service/foundation/openapi"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+)
+
+// Test_OpenAPI is the entry point for validating the API description.
+func Test_OpenAPI(t *testing.T) {
+	t.Parallel()
+
+	app := handlers.APIMux(handlers.APIMuxConfig{
+		Build:    "test",
+		Shutdown: make(chan os.Signal, 1),
+		Log:      zap.NewNop().Sugar(),
+	})
+
+	doc, err := handlersv1.Document(app, "test")
+	if err != nil {
+		t.Fatalf("Should be able to document every route : %s", err)
+	}
+
+	op := doc.Paths["/v1/users/{user_id}"]["get"]
+	if op == nil {
+		t.Fatal("Should have the user query by id operation")
+	}
+
+	if len(op.Security) == 0 {
+		t.Error("Should require a bearer token for an authenticated route")
+	}
+
+	newUser, exists := doc.Components.Schemas["AppNewUser"]
+	if !exists {
+		t.Fatal("Should have a schema for AppNewUser")
+	}
+
+	if newUser.Properties["email"].Format != "email" {
+		t.Logf("got: %v", newUser.Properties["email"].Format)
+		t.Logf("exp: %v", "email")
+		t.Error("Should map the email validate tag to the email format")
+	}
+
+	// -------------------------------------------------------------------------
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/openapi.json", nil)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should receive a status code of 200 for the response : %d", w.Code)
+	}
+
+	var got openapi.Document
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("Should be able to unmarshal the response : %s", err)
+	}
+
+	if got.OpenAPI != openapi.Version {
+		t.Logf("got: %v", got.OpenAPI)
+		t.Logf("exp: %v", openapi.Version)
+		t.Error("Should get back the OpenAPI version")
+	}
+
+	if len(got.Paths) != len(doc.Paths) {
+		t.Logf("got: %v", len(got.Paths))
+		t.Logf("exp: %v", len(doc.Paths))
+		t.Error("Should get back every documented path")
+	}
+
+	// -------------------------------------------------------------------------
+
+	app.Handle(http.MethodGet, "v1", "/undocumented", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	if _, err := handlersv1.Document(app, "test"); err == nil || !strings.Contains(err.Error(), `route "GET /v1/undocumented" is not documented`) {
+		t.Logf("got: %v", err)
+		t.Error("Should fail to document a route that is not described")
+	}
+}
+
+// Test_OpenAPIValidate validates requests against the API description.
+func Test_OpenAPIValidate(t *testing.T) {
+	t.Parallel()
+
+	app := handlers.APIMux(handlers.APIMuxConfig{
+		Build:    "test",
+		Shutdown: make(chan os.Signal, 1),
+		Log:      zap.NewNop().Sugar(),
+	})
+
+	v := openapi.NewValidator(func() (openapi.Document, error) {
+		return handlersv1.Document(app, "test")
+	})
+
+	tt := []struct {
+		name   string
+		method string
+		route  string
+		target string
+		params map[string]string
+		body   string
+		exp    []openapi.FieldError
+	}{
+		{
+			name:   "query",
+			method: http.MethodGet,
+			route:  "/v1/products",
+			target: "/v1/products?page=one&rows=10",
+			exp: []openapi.FieldError{
+				{Field: "page", Err: "page must be an integer"},
+			},
+		},
+		{
+			name:   "path",
+			method: http.MethodDelete,
+			route:  "/v1/products/:product_id",
+			target: "/v1/products/12345",
+			params: map[string]string{"product_id": "12345"},
+			exp: []openapi.FieldError{
+				{Field: "product_id", Err: "invalid UUID length: 5"},
+			},
+		},
+		{
+			name:   "body",
+			method: http.MethodPut,
+			route:  "/v1/products/:product_id",
+			target: "/v1/products/5cf37266-3473-4006-984f-9325122678b7",
+			params: map[string]string{"product_id": "5cf37266-3473-4006-984f-9325122678b7"},
+			body:   `{"name":"Comic Books","quantity":0,"color":"red"}`,
+			exp: []openapi.FieldError{
+				{Field: "quantity", Err: "quantity must be 1 or greater"},
+				{Field: "color", Err: "color is not a known field"},
+			},
+		},
+		{
+			name:   "filter",
+			method: http.MethodGet,
+			route:  "/v1/products",
+			target: "/v1/products?name=ab",
+			exp: []openapi.FieldError{
+				{Field: "name", Err: "name must be at least 3 characters in length"},
+			},
+		},
+		{
+			name:   "casing",
+			method: http.MethodPost,
+			route:  "/v1/products",
+			target: "/v1/products",
+			body:   `{"Name":"Comic Books","Cost":10,"Quantity":5,"UserID":"5cf37266-3473-4006-984f-9325122678b7"}`,
+		},
+		{
+			name:   "valid",
+			method: http.MethodPost,
+			route:  "/v1/products",
+			target: "/v1/products",
+			body:   `{"name":"Comic Books","cost":10,"quantity":5,"userID":"5cf37266-3473-4006-984f-9325122678b7"}`,
+		},
+	}
+
+	for _, tst := range tt {
+		r := httptest.NewRequest(tst.method, tst.target, strings.NewReader(tst.body))
+
+		got, err := v.Validate(r, tst.route, tst.params)
+		if err != nil {
+			t.Fatalf("%s: Should be able to validate the request : %s", tst.name, err)
+		}
+
+		if diff := cmp.Diff(got, tst.exp); diff != "" {
+			t.Errorf("%s: Should get the expected field errors, Diff:\n%s", tst.name, diff)
+		}
+	}
+}
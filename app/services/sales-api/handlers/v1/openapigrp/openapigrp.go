@@ -0,0 +1,37 @@
+// Package openapigrp maintains the group of handlers for the API description.
+package openapigrp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/foundation/openapi"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// Handlers manages the set of OpenAPI endpoints.
+type Handlers struct {
+	app       *web.App
+	cfg       openapi.Config
+	endpoints []openapi.Endpoint
+}
+
+// New constructs a Handlers api for the openapi group.
+func New(app *web.App, cfg openapi.Config, endpoints []openapi.Endpoint) *Handlers {
+	return &Handlers{
+		app:       app,
+		cfg:       cfg,
+		endpoints: endpoints,
+	}
+}
+
+// OpenAPI returns the OpenAPI document describing the routes bound to the app.
+func (h *Handlers) OpenAPI(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	doc, err := openapi.Generate(h.cfg, h.app.Routes(), h.endpoints)
+	if err != nil {
+		return fmt.Errorf("generate: %w", err)
+	}
+
+	return web.Respond(ctx, w, doc, http.StatusOK)
+}
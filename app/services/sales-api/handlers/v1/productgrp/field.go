@@ -0,0 +1,45 @@
+package productgrp
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/business/core/product"
+	"github.com/ardanlabs/service/business/web/v1/fieldset"
+	"github.com/ardanlabs/service/foundation/openapi"
+)
+
+// FieldParams documents the query parameters understood by parseFields and
+// parseInclude.
+var FieldParams = []openapi.Parameter{
+	openapi.QueryParam("fields", "string", ""),
+	openapi.QueryParam("include", "string", ""),
+}
+
+// includeUser embeds the owner of each product.
+const includeUser = "user"
+
+// fields maps the attributes of AppProductDetails to the product fields they
+// are built from.
+var selectFields = map[string]string{
+	"id":          product.FieldProdID,
+	"userID":      product.FieldUserID,
+	"name":        product.FieldName,
+	"cost":        product.FieldCost,
+	"quantity":    product.FieldQuantity,
+	"userName":    product.FieldUserID,
+	"dateCreated": product.FieldDateCreated,
+	"dateUpdated": product.FieldDateUpdated,
+	"highlight":   "",
+}
+
+var includes = map[string]struct{}{
+	includeUser: {},
+}
+
+func parseFields(r *http.Request) ([]string, error) {
+	return fieldset.Parse(r, selectFields)
+}
+
+func parseInclude(r *http.Request) (map[string]bool, error) {
+	return fieldset.ParseInclude(r, includes)
+}
@@ -2,47 +2,48 @@ package productgrp
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/ardanlabs/service/business/core/product"
-	"github.com/ardanlabs/service/business/sys/validate"
-	"github.com/google/uuid"
+	"github.com/ardanlabs/service/business/web/v1/filtering"
+	"github.com/ardanlabs/service/foundation/openapi"
 )
 
+// searchDescription documents the search parameter and the format of the
+// highlight it adds to the results.
+const searchDescription = "Searches the names of the products. Each product found has a highlight of its name that is HTML escaped with the matching words wrapped in <b> tags."
+
+// FilterParams documents the query parameters understood by parseFilter,
+// with the rules of product.QueryFilter. The OpenAPI middleware checks them
+// before the handler runs, so parseFilter only has to convert them.
+var FilterParams = []openapi.Parameter{
+	openapi.QueryParam("product_id", "string", ""),
+	openapi.QueryParam("cost", "number", "double"),
+	openapi.QueryParam("min_cost", "number", "double"),
+	openapi.QueryParam("max_cost", "number", "double"),
+	openapi.QueryParam("quantity", "integer", "int64"),
+	openapi.QueryParam("min_quantity", "integer", "int64"),
+	openapi.QueryParam("max_quantity", "integer", "int64"),
+	openapi.QueryParam("start_updated_date", "string", "date-time"),
+	openapi.QueryParam("end_updated_date", "string", "date-time"),
+	openapi.QueryParam("name", "string", "").WithMinLength(3),
+	openapi.QueryParam("q", "string", "").WithMinLength(2).WithDescription(searchDescription),
+}
+
 func parseFilter(r *http.Request) (product.QueryFilter, error) {
-	values := r.URL.Query()
+	p := filtering.New(r)
 
 	var filter product.QueryFilter
 
-	if productID := values.Get("product_id"); productID != "" {
-		id, err := uuid.Parse(productID)
-		if err != nil {
-			return product.QueryFilter{}, validate.NewFieldsError("product_id", err)
-		}
-		filter.WithProductID(id)
-	}
-
-	if cost := values.Get("cost"); cost != "" {
-		cst, err := strconv.ParseFloat(cost, 64)
-		if err != nil {
-			return product.QueryFilter{}, validate.NewFieldsError("cost", err)
-		}
-		filter.WithCost(cst)
-	}
-
-	if quantity := values.Get("quantity"); quantity != "" {
-		qua, err := strconv.ParseInt(quantity, 10, 64)
-		if err != nil {
-			return product.QueryFilter{}, validate.NewFieldsError("quantity", err)
-		}
-		filter.WithQuantity(int(qua))
-	}
-
-	if name := values.Get("name"); name != "" {
-		filter.WithName(name)
-	}
+	filter.IDs = filtering.List(p, "product_id", filtering.UUID)
+	filter.Name = filtering.Value(p, "name", filtering.String)
+	filter.Cost = filtering.Value(p, "cost", filtering.Float)
+	filter.MinCost, filter.MaxCost = filtering.Range(p, "cost", filtering.Float)
+	filter.Quantity = filtering.Value(p, "quantity", filtering.Int)
+	filter.MinQuantity, filter.MaxQuantity = filtering.Range(p, "quantity", filtering.Int)
+	filter.StartUpdatedDate, filter.EndUpdatedDate = filtering.DateRange(p, "updated_date")
+	filter.Search = filtering.Value(p, "q", filtering.String)
 
-	if err := filter.Validate(); err != nil {
+	if err := p.Err(); err != nil {
 		return product.QueryFilter{}, err
 	}
 
@@ -35,20 +35,39 @@ func toAppProduct(prd product.Product) AppProduct {
 
 // =============================================================================
 
+// AppProductUser represents the owner of a product embedded in its details.
+type AppProductUser struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+	Department string `json:"department"`
+}
+
+func toAppProductUser(usr user.User) *AppProductUser {
+	return &AppProductUser{
+		ID:         usr.ID.String(),
+		Name:       usr.Name,
+		Email:      usr.Email.Address,
+		Department: usr.Department,
+	}
+}
+
 // AppProductDetails represents an individual product.
 type AppProductDetails struct {
-	ID          string  `json:"id"`
-	UserID      string  `json:"userID"`
-	Name        string  `json:"name"`
-	Cost        float64 `json:"cost"`
-	Quantity    int     `json:"quantity"`
-	UserName    string  `json:"userName"`
-	DateCreated string  `json:"dateCreated"`
-	DateUpdated string  `json:"dateUpdated"`
+	ID          string          `json:"id"`
+	UserID      string          `json:"userID"`
+	Name        string          `json:"name"`
+	Cost        float64         `json:"cost"`
+	Quantity    int             `json:"quantity"`
+	UserName    string          `json:"userName"`
+	DateCreated string          `json:"dateCreated"`
+	DateUpdated string          `json:"dateUpdated"`
+	Highlight   string          `json:"highlight,omitempty"`
+	User        *AppProductUser `json:"user,omitempty"`
 }
 
-func toAppProductDetails(prd product.Product, usr user.User) AppProductDetails {
-	return AppProductDetails{
+func toAppProductDetails(prd product.Product, usr user.User, embedUser bool) AppProductDetails {
+	app := AppProductDetails{
 		ID:          prd.ID.String(),
 		Name:        prd.Name,
 		Cost:        prd.Cost,
@@ -57,13 +76,20 @@ func toAppProductDetails(prd product.Product, usr user.User) AppProductDetails {
 		UserName:    usr.Name,
 		DateCreated: prd.DateCreated.Format(time.RFC3339),
 		DateUpdated: prd.DateUpdated.Format(time.RFC3339),
+		Highlight:   prd.Highlight,
 	}
+
+	if embedUser {
+		app.User = toAppProductUser(usr)
+	}
+
+	return app
 }
 
-func toAppProductsDetails(prds []product.Product, usrs map[uuid.UUID]user.User) []AppProductDetails {
+func toAppProductsDetails(prds []product.Product, usrs map[uuid.UUID]user.User, embedUser bool) []AppProductDetails {
 	items := make([]AppProductDetails, len(prds))
 	for i, prd := range prds {
-		items[i] = toAppProductDetails(prd, usrs[prd.UserID])
+		items[i] = toAppProductDetails(prd, usrs[prd.UserID], embedUser)
 	}
 
 	return items
@@ -1,12 +1,10 @@
 package productgrp
 
 import (
-	"errors"
 	"net/http"
 
 	"github.com/ardanlabs/service/business/core/product"
 	"github.com/ardanlabs/service/business/data/order"
-	"github.com/ardanlabs/service/business/sys/validate"
 )
 
 var orderByFields = map[string]struct{}{
@@ -20,14 +18,5 @@ var orderByFields = map[string]struct{}{
 }
 
 func parseOrder(r *http.Request) (order.By, error) {
-	orderBy, err := order.Parse(r, product.DefaultOrderBy)
-	if err != nil {
-		return order.By{}, err
-	}
-
-	if _, exists := orderByFields[orderBy.Field]; !exists {
-		return order.By{}, validate.NewFieldsError(orderBy.Field, errors.New("order field does not exist"))
-	}
-
-	return orderBy, nil
+	return order.Parse(r, product.DefaultOrderBy, orderByFields)
 }
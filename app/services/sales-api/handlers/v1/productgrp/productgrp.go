@@ -6,12 +6,14 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/ardanlabs/service/business/core/product"
 	"github.com/ardanlabs/service/business/core/user"
 	"github.com/ardanlabs/service/business/sys/validate"
 	"github.com/ardanlabs/service/business/web/auth"
 	v1 "github.com/ardanlabs/service/business/web/v1"
+	"github.com/ardanlabs/service/business/web/v1/fieldset"
 	"github.com/ardanlabs/service/business/web/v1/paging"
 	"github.com/ardanlabs/service/foundation/web"
 	"github.com/google/uuid"
@@ -133,7 +135,27 @@ func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Req
 		return err
 	}
 
-	prds, err := h.product.Query(ctx, filter, orderBy, page.Number, page.RowsPerPage)
+	fields, err := parseFields(r)
+	if err != nil {
+		return err
+	}
+
+	include, err := parseInclude(r)
+	if err != nil {
+		return err
+	}
+
+	embedUser := include[includeUser]
+	loadUsers := embedUser || fieldset.Has(fields, "userName")
+
+	// Only the columns needed to build the selected attributes are read,
+	// which includes the user id when the owners need to be loaded.
+	prdFields := fieldset.Map(fields, selectFields)
+	if len(prdFields) > 0 && loadUsers {
+		prdFields = append(prdFields, product.FieldUserID)
+	}
+
+	prds, err := h.product.Query(ctx, filter, orderBy, page.Number, page.RowsPerPage, prdFields...)
 	if err != nil {
 		return fmt.Errorf("query: %w", err)
 	}
@@ -142,7 +164,7 @@ func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Req
 	// Capture the unique set of users
 
 	users := make(map[uuid.UUID]user.User)
-	if len(prds) > 0 {
+	if loadUsers && len(prds) > 0 {
 		for _, prd := range prds {
 			users[prd.UserID] = user.User{}
 		}
@@ -169,7 +191,24 @@ func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Req
 		return fmt.Errorf("count: %w", err)
 	}
 
-	return web.Respond(ctx, w, paging.NewResponse(toAppProductsDetails(prds, users), total, page.Number, page.RowsPerPage), http.StatusOK)
+	items := toAppProductsDetails(prds, users, embedUser)
+
+	// A page can change without any of its products being updated, such as
+	// when a product is deleted, so only the ETag is used to validate it.
+	if len(fields) == 0 {
+		return web.RespondConditional(ctx, w, r, paging.NewResponse(items, total, page.Number, page.RowsPerPage), time.Time{})
+	}
+
+	if embedUser {
+		fields = append(fields, includeUser)
+	}
+
+	selected, err := fieldset.Select(items, fields)
+	if err != nil {
+		return fmt.Errorf("select: %w", err)
+	}
+
+	return web.RespondConditional(ctx, w, r, paging.NewResponse(selected, total, page.Number, page.RowsPerPage), time.Time{})
 }
 
 // QueryByID returns a product by its ID.
@@ -189,5 +228,5 @@ func (h *Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http
 		}
 	}
 
-	return web.Respond(ctx, w, toAppProduct(prd), http.StatusOK)
+	return web.RespondConditional(ctx, w, r, toAppProduct(prd), prd.DateUpdated)
 }
@@ -0,0 +1,52 @@
+package reportgrp
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/business/cview/report"
+	"github.com/ardanlabs/service/business/web/v1/filtering"
+)
+
+func parseActivityFilter(r *http.Request) (report.ActivityFilter, report.Interval, error) {
+	p := filtering.New(r)
+
+	var filter report.ActivityFilter
+
+	filter.UserID = filtering.Value(p, "user_id", filtering.UUID)
+	filter.StartDate, filter.EndDate = filtering.DateRange(p, "date")
+
+	interval := report.IntervalDay
+	if v := filtering.Value(p, "interval", report.ParseInterval); v != nil {
+		interval = *v
+	}
+
+	if err := p.Err(); err != nil {
+		return report.ActivityFilter{}, report.Interval{}, err
+	}
+
+	if err := filter.Validate(); err != nil {
+		return report.ActivityFilter{}, report.Interval{}, err
+	}
+
+	return filter, interval, nil
+}
+
+func parseInventoryFilter(r *http.Request) (report.InventoryFilter, error) {
+	p := filtering.New(r)
+
+	var filter report.InventoryFilter
+
+	filter.UserID = filtering.Value(p, "user_id", filtering.UUID)
+	filter.Department = filtering.Value(p, "department", filtering.String)
+	filter.IncludeEmpty = filtering.Value(p, "include_empty", filtering.Bool)
+
+	if err := p.Err(); err != nil {
+		return report.InventoryFilter{}, err
+	}
+
+	if err := filter.Validate(); err != nil {
+		return report.InventoryFilter{}, err
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,45 @@
+package reportgrp
+
+import (
+	"time"
+
+	"github.com/ardanlabs/service/business/cview/report"
+)
+
+// AppActivity represents the products created within one time bucket.
+type AppActivity struct {
+	Start      string  `json:"start"`
+	TotalCount int     `json:"totalCount"`
+	TotalValue float64 `json:"totalValue"`
+}
+
+func toAppActivity(act report.Activity) AppActivity {
+	return AppActivity{
+		Start:      act.Start.Format(time.RFC3339),
+		TotalCount: act.TotalCount,
+		TotalValue: act.TotalValue,
+	}
+}
+
+// =============================================================================
+
+// AppInventory represents the value of the products held by a user.
+type AppInventory struct {
+	UserID        string  `json:"userID"`
+	UserName      string  `json:"userName"`
+	Department    string  `json:"department"`
+	TotalCount    int     `json:"totalCount"`
+	TotalQuantity int     `json:"totalQuantity"`
+	TotalValue    float64 `json:"totalValue"`
+}
+
+func toAppInventory(inv report.Inventory) AppInventory {
+	return AppInventory{
+		UserID:        inv.UserID.String(),
+		UserName:      inv.UserName,
+		Department:    inv.Department,
+		TotalCount:    inv.TotalCount,
+		TotalQuantity: inv.TotalQuantity,
+		TotalValue:    inv.TotalValue,
+	}
+}
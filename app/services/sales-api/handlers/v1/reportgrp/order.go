@@ -0,0 +1,21 @@
+package reportgrp
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/business/cview/report"
+	"github.com/ardanlabs/service/business/data/order"
+)
+
+var orderByInventoryFields = map[string]struct{}{
+	report.OrderByUserID:        {},
+	report.OrderByUserName:      {},
+	report.OrderByDepartment:    {},
+	report.OrderByTotalCount:    {},
+	report.OrderByTotalQuantity: {},
+	report.OrderByTotalValue:    {},
+}
+
+func parseInventoryOrder(r *http.Request) (order.By, error) {
+	return order.Parse(r, report.DefaultInventoryOrderBy, orderByInventoryFields)
+}
@@ -0,0 +1,81 @@
+// Package reportgrp maintains the group of handlers for reporting access.
+package reportgrp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ardanlabs/service/business/cview/report"
+	"github.com/ardanlabs/service/business/web/v1/paging"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// Handlers manages the set of report endpoints.
+type Handlers struct {
+	report *report.Core
+}
+
+// New constructs a handlers for route access.
+func New(report *report.Core) *Handlers {
+	return &Handlers{
+		report: report,
+	}
+}
+
+// QueryActivity returns the products created in each time bucket.
+func (h *Handlers) QueryActivity(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	filter, interval, err := parseActivityFilter(r)
+	if err != nil {
+		return err
+	}
+
+	acts, err := h.report.QueryActivity(ctx, filter, interval)
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	items := make([]AppActivity, len(acts))
+	for i, act := range acts {
+		items[i] = toAppActivity(act)
+	}
+
+	return web.RespondConditional(ctx, w, r, items, time.Time{})
+}
+
+// QueryInventory returns a page of the value of the products held by each
+// user.
+func (h *Handlers) QueryInventory(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := paging.ParseRequest(r)
+	if err != nil {
+		return err
+	}
+
+	filter, err := parseInventoryFilter(r)
+	if err != nil {
+		return err
+	}
+
+	orderBy, err := parseInventoryOrder(r)
+	if err != nil {
+		return err
+	}
+
+	invs, err := h.report.QueryInventory(ctx, filter, orderBy, page.Number, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	items := make([]AppInventory, len(invs))
+	for i, inv := range invs {
+		items[i] = toAppInventory(inv)
+	}
+
+	total, err := h.report.CountInventory(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("count: %w", err)
+	}
+
+	return web.RespondConditional(ctx, w, r, paging.NewResponse(items, total, page.Number, page.RowsPerPage), time.Time{})
+}
@@ -0,0 +1,50 @@
+package v1_test
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/ardanlabs/service/app/services/sales-api/handlers"
+	"go.uber.org/zap"
+)
+
+// Test_Routes is the entry point for validating the route table.
+func Test_Routes(t *testing.T) {
+	t.Parallel()
+
+	app := handlers.APIMux(handlers.APIMuxConfig{
+		Shutdown: make(chan os.Signal, 1),
+		Log:      zap.NewNop().Sugar(),
+	})
+
+	// These are the only routes that can be called without a token.
+	public := map[string]bool{
+		"GET /v1/readiness":        true,
+		"GET /v1/liveness":         true,
+		"GET /v1/openapi.json":     true,
+		"GET /v1/users/token/:kid": true,
+	}
+
+	routes := app.Routes()
+	if len(routes) == 0 {
+		t.Fatal("Should have routes registered with the application")
+	}
+
+	for _, route := range routes {
+		key := route.Method + " " + route.Path
+		if public[key] {
+			continue
+		}
+
+		// Browsers never send credentials with CORS preflight requests.
+		if route.Method == http.MethodOptions {
+			continue
+		}
+
+		if !route.HasMiddleware("mid.Authenticate") {
+			t.Logf("got: %v", route.Middleware)
+			t.Errorf("Should have authentication for route %q", key)
+		}
+	}
+}
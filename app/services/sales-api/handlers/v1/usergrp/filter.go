@@ -3,79 +3,85 @@ package usergrp
 import (
 	"net/http"
 	"net/mail"
-	"time"
 
 	"github.com/ardanlabs/service/business/core/user"
 	"github.com/ardanlabs/service/business/cview/user/summary"
-	"github.com/ardanlabs/service/business/sys/validate"
-	"github.com/google/uuid"
+	"github.com/ardanlabs/service/business/web/v1/filtering"
+	"github.com/ardanlabs/service/foundation/openapi"
 )
 
+// searchDescription documents the search parameter and the format of the
+// highlight it adds to the results.
+const searchDescription = "Searches the names and departments of the users. Each user found has a highlight of their name that is HTML escaped with the matching words wrapped in <b> tags."
+
+// FilterParams documents the query parameters understood by parseFilter,
+// with the rules of user.QueryFilter. The OpenAPI middleware checks them
+// before the handler runs, so parseFilter only has to convert them.
+var FilterParams = []openapi.Parameter{
+	openapi.QueryParam("user_id", "string", ""),
+	openapi.QueryParam("email", "string", "email"),
+	openapi.QueryParam("roles", "string", ""),
+	openapi.QueryParam("department", "string", ""),
+	openapi.QueryParam("enabled", "boolean", ""),
+	openapi.QueryParam("start_created_date", "string", "date-time"),
+	openapi.QueryParam("end_created_date", "string", "date-time"),
+	openapi.QueryParam("start_updated_date", "string", "date-time"),
+	openapi.QueryParam("end_updated_date", "string", "date-time"),
+	openapi.QueryParam("name", "string", "").WithMinLength(3),
+	openapi.QueryParam("q", "string", "").WithMinLength(2).WithDescription(searchDescription),
+}
+
 func parseFilter(r *http.Request) (user.QueryFilter, error) {
-	values := r.URL.Query()
+	p := filtering.New(r)
 
 	var filter user.QueryFilter
 
-	if userID := values.Get("user_id"); userID != "" {
-		id, err := uuid.Parse(userID)
-		if err != nil {
-			return user.QueryFilter{}, validate.NewFieldsError("user_id", err)
-		}
-		filter.WithUserID(id)
-	}
-
-	if email := values.Get("email"); email != "" {
-		addr, err := mail.ParseAddress(email)
-		if err != nil {
-			return user.QueryFilter{}, validate.NewFieldsError("email", err)
-		}
-		filter.WithEmail(*addr)
-	}
-
-	if createdDate := values.Get("start_created_date"); createdDate != "" {
-		t, err := time.Parse(time.RFC3339, createdDate)
-		if err != nil {
-			return user.QueryFilter{}, validate.NewFieldsError("start_created_date", err)
-		}
-		filter.WithStartDateCreated(t)
-	}
-
-	if createdDate := values.Get("end_created_date"); createdDate != "" {
-		t, err := time.Parse(time.RFC3339, createdDate)
-		if err != nil {
-			return user.QueryFilter{}, validate.NewFieldsError("end_created_date", err)
-		}
-		filter.WithEndCreatedDate(t)
-	}
-
-	if name := values.Get("name"); name != "" {
-		filter.WithName(name)
-	}
-
-	if err := filter.Validate(); err != nil {
+	filter.IDs = filtering.List(p, "user_id", filtering.UUID)
+	filter.Name = filtering.Value(p, "name", filtering.String)
+	filter.Email = filtering.Value(p, "email", parseEmail)
+	filter.Roles = filtering.List(p, "roles", user.ParseRole)
+	filter.Department = filtering.Value(p, "department", filtering.String)
+	filter.Enabled = filtering.Value(p, "enabled", filtering.Bool)
+	filter.StartCreatedDate, filter.EndCreatedDate = filtering.DateRange(p, "created_date")
+	filter.StartUpdatedDate, filter.EndUpdatedDate = filtering.DateRange(p, "updated_date")
+	filter.Search = filtering.Value(p, "q", filtering.String)
+
+	if err := p.Err(); err != nil {
 		return user.QueryFilter{}, err
 	}
 
 	return filter, nil
 }
 
+func parseEmail(v string) (mail.Address, error) {
+	addr, err := mail.ParseAddress(v)
+	if err != nil {
+		return mail.Address{}, err
+	}
+	return *addr, nil
+}
+
 // =============================================================================
 
+// SummaryFilterParams documents the query parameters understood by
+// parseSummaryFilter, with the rules of summary.QueryFilter.
+var SummaryFilterParams = []openapi.Parameter{
+	openapi.QueryParam("user_id", "string", "uuid"),
+	openapi.QueryParam("user_name", "string", "").WithMinLength(3),
+	openapi.QueryParam("include_empty", "boolean", ""),
+}
+
 func parseSummaryFilter(r *http.Request) (summary.QueryFilter, error) {
-	values := r.URL.Query()
+	p := filtering.New(r)
 
 	var filter summary.QueryFilter
 
-	if userID := values.Get("user_id"); userID != "" {
-		id, err := uuid.Parse(userID)
-		if err != nil {
-			return summary.QueryFilter{}, validate.NewFieldsError("user_id", err)
-		}
-		filter.WithUserID(id)
-	}
+	filter.UserID = filtering.Value(p, "user_id", filtering.UUID)
+	filter.UserName = filtering.Value(p, "user_name", filtering.String)
+	filter.IncludeEmpty = filtering.Value(p, "include_empty", filtering.Bool)
 
-	if userName := values.Get("user_name"); userName != "" {
-		filter.WithUserName(userName)
+	if err := p.Err(); err != nil {
+		return summary.QueryFilter{}, err
 	}
 
 	return filter, nil
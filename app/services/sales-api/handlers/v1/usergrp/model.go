@@ -21,6 +21,7 @@ type AppUser struct {
 	Enabled      bool     `json:"enabled"`
 	DateCreated  string   `json:"dateCreated"`
 	DateUpdated  string   `json:"dateUpdated"`
+	Highlight    string   `json:"highlight,omitempty"`
 }
 
 func toAppUser(usr user.User) AppUser {
@@ -39,6 +40,7 @@ func toAppUser(usr user.User) AppUser {
 		Enabled:      usr.Enabled,
 		DateCreated:  usr.DateCreated.Format(time.RFC3339),
 		DateUpdated:  usr.DateUpdated.Format(time.RFC3339),
+		Highlight:    usr.Highlight,
 	}
 }
 
@@ -163,3 +165,10 @@ func toAppSummary(smm summary.Summary) AppSummary {
 		TotalCost:  smm.TotalCost,
 	}
 }
+
+// =============================================================================
+
+// AppToken represents an API token for an authenticated user.
+type AppToken struct {
+	Token string `json:"token"`
+}
@@ -1,13 +1,11 @@
 package usergrp
 
 import (
-	"errors"
 	"net/http"
 
 	"github.com/ardanlabs/service/business/core/user"
 	"github.com/ardanlabs/service/business/cview/user/summary"
 	"github.com/ardanlabs/service/business/data/order"
-	"github.com/ardanlabs/service/business/sys/validate"
 )
 
 var orderByFields = map[string]struct{}{
@@ -19,16 +17,7 @@ var orderByFields = map[string]struct{}{
 }
 
 func parseOrder(r *http.Request) (order.By, error) {
-	orderBy, err := order.Parse(r, user.DefaultOrderBy)
-	if err != nil {
-		return order.By{}, err
-	}
-
-	if _, exists := orderByFields[orderBy.Field]; !exists {
-		return order.By{}, validate.NewFieldsError(orderBy.Field, errors.New("order field does not exist"))
-	}
-
-	return orderBy, nil
+	return order.Parse(r, user.DefaultOrderBy, orderByFields)
 }
 
 // =============================================================================
@@ -39,14 +28,5 @@ var orderBySummaryFields = map[string]struct{}{
 }
 
 func parseSummaryOrder(r *http.Request) (order.By, error) {
-	orderBy, err := order.Parse(r, user.DefaultOrderBy)
-	if err != nil {
-		return order.By{}, err
-	}
-
-	if _, exists := orderBySummaryFields[orderBy.Field]; !exists {
-		return order.By{}, validate.NewFieldsError(orderBy.Field, errors.New("order field does not exist"))
-	}
-
-	return orderBy, nil
+	return order.Parse(r, summary.DefaultOrderBy, orderBySummaryFields)
 }
@@ -143,7 +143,9 @@ func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Req
 		return fmt.Errorf("count: %w", err)
 	}
 
-	return web.Respond(ctx, w, paging.NewResponse(items, total, page.Number, page.RowsPerPage), http.StatusOK)
+	// A page can change without any of its users being updated, such as
+	// when a user is deleted, so only the ETag is used to validate it.
+	return web.RespondConditional(ctx, w, r, paging.NewResponse(items, total, page.Number, page.RowsPerPage), time.Time{})
 }
 
 // QueryByID returns a user by its ID.
@@ -160,7 +162,7 @@ func (h *Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http
 		}
 	}
 
-	return web.Respond(ctx, w, toAppUser(usr), http.StatusOK)
+	return web.RespondConditional(ctx, w, r, toAppUser(usr), usr.DateUpdated)
 }
 
 // QuerySummary returns a list of user summary data with paging.
@@ -195,7 +197,7 @@ func (h *Handlers) QuerySummary(ctx context.Context, w http.ResponseWriter, r *h
 		return fmt.Errorf("count: %w", err)
 	}
 
-	return web.Respond(ctx, w, paging.NewResponse(items, total, page.Number, page.RowsPerPage), http.StatusOK)
+	return web.RespondConditional(ctx, w, r, paging.NewResponse(items, total, page.Number, page.RowsPerPage), time.Time{})
 }
 
 // Token provides an API token for the authenticated user.
@@ -237,9 +239,7 @@ func (h *Handlers) Token(ctx context.Context, w http.ResponseWriter, r *http.Req
 		Roles: usr.Roles,
 	}
 
-	var tkn struct {
-		Token string `json:"token"`
-	}
+	var tkn AppToken
 	tkn.Token, err = h.auth.GenerateToken(kid, claims)
 	if err != nil {
 		return fmt.Errorf("generatetoken: %w", err)
@@ -4,9 +4,12 @@ package v1
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/ardanlabs/service/app/services/sales-api/handlers/v1/checkgrp"
+	"github.com/ardanlabs/service/app/services/sales-api/handlers/v1/openapigrp"
 	"github.com/ardanlabs/service/app/services/sales-api/handlers/v1/productgrp"
+	"github.com/ardanlabs/service/app/services/sales-api/handlers/v1/reportgrp"
 	"github.com/ardanlabs/service/app/services/sales-api/handlers/v1/usergrp"
 	"github.com/ardanlabs/service/business/core/event"
 	"github.com/ardanlabs/service/business/core/product"
@@ -14,62 +17,128 @@ import (
 	"github.com/ardanlabs/service/business/core/user"
 	"github.com/ardanlabs/service/business/core/user/stores/usercache"
 	"github.com/ardanlabs/service/business/core/user/stores/userdb"
+	"github.com/ardanlabs/service/business/cview/report"
+	"github.com/ardanlabs/service/business/cview/report/stores/reportdb"
 	"github.com/ardanlabs/service/business/cview/user/summary"
 	"github.com/ardanlabs/service/business/cview/user/summary/stores/summarydb"
+	"github.com/ardanlabs/service/business/sys/database"
 	"github.com/ardanlabs/service/business/web/auth"
+	"github.com/ardanlabs/service/business/web/idempotency"
+	"github.com/ardanlabs/service/business/web/idempotency/stores/idempotencydb"
+	"github.com/ardanlabs/service/business/web/ratelimit"
 	"github.com/ardanlabs/service/business/web/v1/mid"
+	"github.com/ardanlabs/service/foundation/openapi"
 	"github.com/ardanlabs/service/foundation/web"
-	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
 )
 
+// version is the path prefix for every route in this version of the api.
+const version = "v1"
+
 // Config contains all the mandatory systems required by handlers.
 type Config struct {
-	Build string
-	Log   *zap.SugaredLogger
-	Auth  *auth.Auth
-	DB    *sqlx.DB
+	Build                  string
+	Log                    *zap.SugaredLogger
+	Auth                   *auth.Auth
+	DB                     *database.DB
+	QueryTimeout           time.Duration
+	Limiter                *ratelimit.Limiter
+	PublicRate             ratelimit.Rate
+	UserRate               ratelimit.Rate
+	TrustedProxies         mid.TrustedProxies
+	IdempotencyTTL         time.Duration
+	IdempotencyLockTimeout time.Duration
+	CORS                   mid.CORSPolicy
+	PublicCORS             mid.CORSPolicy
 }
 
 // Routes binds all the version 1 routes.
 func Routes(app *web.App, cfg Config) {
-	const version = "v1"
-
 	envCore := event.NewCore(cfg.Log)
 	usrCore := user.NewCore(envCore, usercache.NewStore(cfg.Log, userdb.NewStore(cfg.Log, cfg.DB)))
 	prdCore := product.NewCore(cfg.Log, envCore, usrCore, productdb.NewStore(cfg.Log, cfg.DB))
 	smmCore := summary.NewCore(summarydb.NewStore(cfg.Log, cfg.DB))
+	rptCore := report.NewCore(cfg.Log, reportdb.NewStore(cfg.Log, cfg.DB))
+	idmCore := idempotency.NewCore(idempotencydb.NewStore(cfg.Log, cfg.DB), cfg.IdempotencyTTL, cfg.IdempotencyLockTimeout)
 
 	authen := mid.Authenticate(cfg.Auth)
 	ruleAdmin := mid.Authorize(cfg.Auth, auth.RuleAdminOnly)
 	ruleAdminOrSubject := mid.Authorize(cfg.Auth, auth.RuleAdminOrSubject)
+	queryTimeout := web.Timeout(cfg.QueryTimeout)
+	publicLimit := mid.RateLimit(cfg.Limiter, "public", cfg.PublicRate, cfg.TrustedProxies)
+	userLimit := mid.RateLimit(cfg.Limiter, "user", cfg.UserRate, cfg.TrustedProxies)
+	idempotent := mid.Idempotency(cfg.Log, idmCore)
+	cors := mid.Cors(cfg.CORS)
+	publicCors := mid.Cors(cfg.PublicCORS)
+
+	// Requests are validated against the OpenAPI document once the caller
+	// has been authorized, so it must be the last middleware in each group.
+	valid := mid.OpenAPI(openapi.NewValidator(func() (openapi.Document, error) {
+		return Document(app, cfg.Build)
+	}))
 
 	// -------------------------------------------------------------------------
 
 	cgh := checkgrp.New(cfg.Build, cfg.DB)
 
-	app.Handle(http.MethodGet, version, "/readiness", cgh.Readiness)
-	app.Handle(http.MethodGet, version, "/liveness", cgh.Liveness)
+	// The health checks are not rate limited since the orchestrator calls
+	// them from the same address on a schedule.
+	checks := app.Group(version, valid)
+	checks.Handle(http.MethodGet, "/readiness", cgh.Readiness)
+	checks.Handle(http.MethodGet, "/liveness", cgh.Liveness)
+
+	// -------------------------------------------------------------------------
+
+	public := app.Group(version, publicCors, publicLimit, valid)
+	if cfg.PublicCORS.Enabled() {
+		public.HandleOptions(mid.Preflight(cfg.PublicCORS))
+	}
+
+	ogh := openapigrp.New(app, openAPIConfig(cfg.Build), endpoints())
+
+	public.Handle(http.MethodGet, "/openapi.json", ogh.OpenAPI)
+
+	// -------------------------------------------------------------------------
+
+	// The authenticated routes share a CORS policy. It runs ahead of
+	// authentication so the client can read the error responses.
+	api := app.Group(version, cors)
+	if cfg.CORS.Enabled() {
+		api.HandleOptions(mid.Preflight(cfg.CORS))
+	}
 
 	// -------------------------------------------------------------------------
 
 	ugh := usergrp.New(usrCore, smmCore, cfg.Auth)
 
-	app.Handle(http.MethodGet, version, "/users/token/:kid", ugh.Token)
-	app.Handle(http.MethodGet, version, "/users", ugh.Query, authen, ruleAdmin)
-	app.Handle(http.MethodGet, version, "/users/:user_id", ugh.QueryByID, authen, ruleAdminOrSubject)
-	app.Handle(http.MethodGet, version, "/users/summary", ugh.QuerySummary, authen, ruleAdmin)
-	app.Handle(http.MethodPost, version, "/users", ugh.Create, authen, ruleAdmin)
-	app.Handle(http.MethodPut, version, "/users/:user_id", ugh.Update, authen, ruleAdminOrSubject)
-	app.Handle(http.MethodDelete, version, "/users/:user_id", ugh.Delete, authen, ruleAdminOrSubject)
+	public.Handle(http.MethodGet, "/users/token/:kid", ugh.Token)
+
+	admin := api.Group("", authen, userLimit, ruleAdmin, valid)
+	admin.Handle(http.MethodGet, "/users", ugh.Query, queryTimeout)
+	admin.Handle(http.MethodGet, "/users/summary", ugh.QuerySummary, queryTimeout)
+	admin.Handle(http.MethodPost, "/users", ugh.Create, idempotent)
+
+	subject := api.Group("", authen, userLimit, ruleAdminOrSubject, valid)
+	subject.Handle(http.MethodGet, "/users/:user_id", ugh.QueryByID)
+	subject.Handle(http.MethodPut, "/users/:user_id", ugh.Update)
+	subject.Handle(http.MethodDelete, "/users/:user_id", ugh.Delete)
 
 	// -------------------------------------------------------------------------
 
 	pgh := productgrp.New(prdCore, usrCore, cfg.Auth)
 
-	app.Handle(http.MethodGet, version, "/products", pgh.Query, authen)
-	app.Handle(http.MethodGet, version, "/products/:product_id", pgh.QueryByID, authen)
-	app.Handle(http.MethodPost, version, "/products", pgh.Create, authen)
-	app.Handle(http.MethodPut, version, "/products/:product_id", pgh.Update, authen)
-	app.Handle(http.MethodDelete, version, "/products/:product_id", pgh.Delete, authen)
+	products := api.Group("products", authen, userLimit, valid)
+	products.Handle(http.MethodGet, "", pgh.Query, queryTimeout)
+	products.Handle(http.MethodGet, "/:product_id", pgh.QueryByID)
+	products.Handle(http.MethodPost, "", pgh.Create, idempotent)
+	products.Handle(http.MethodPut, "/:product_id", pgh.Update)
+	products.Handle(http.MethodDelete, "/:product_id", pgh.Delete)
+
+	// -------------------------------------------------------------------------
+
+	rgh := reportgrp.New(rptCore)
+
+	reports := api.Group("reports", authen, userLimit, ruleAdmin, valid)
+	reports.Handle(http.MethodGet, "/activity", rgh.QueryActivity, queryTimeout)
+	reports.Handle(http.MethodGet, "/inventory", rgh.QueryInventory, queryTimeout)
 }
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"expvar"
 	"fmt"
@@ -9,23 +10,44 @@ import (
 	"os"
 	"os/signal"
 	"runtime"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/ardanlabs/conf/v3"
 	"github.com/ardanlabs/service/app/services/sales-api/handlers"
-	database "github.com/ardanlabs/service/business/sys/database/pgx"
+	"github.com/ardanlabs/service/business/core/user"
+	"github.com/ardanlabs/service/business/cview/report"
+	"github.com/ardanlabs/service/business/cview/report/stores/reportdb"
+	"github.com/ardanlabs/service/business/sys/database"
+	"github.com/ardanlabs/service/business/sys/database/querylog"
 	"github.com/ardanlabs/service/business/web/auth"
+	"github.com/ardanlabs/service/business/web/idempotency"
+	"github.com/ardanlabs/service/business/web/idempotency/stores/idempotencydb"
+	"github.com/ardanlabs/service/business/web/ratelimit"
+	"github.com/ardanlabs/service/business/web/ratelimit/stores/ratelimitdb"
+	"github.com/ardanlabs/service/business/web/ratelimit/stores/ratelimitmem"
 	"github.com/ardanlabs/service/business/web/v1/debug"
+	"github.com/ardanlabs/service/business/web/v1/mid"
+	"github.com/ardanlabs/service/foundation/certs"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/vault"
+	"github.com/ardanlabs/service/foundation/web"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc/credentials"
 )
 
 /*
@@ -36,21 +58,30 @@ import (
 var build = "develop"
 
 func main() {
-	log, err := logger.New("SALES-API")
+
+	// The levels start at info and are updated from the configuration once it
+	// is parsed. The database query logs are sampled since they are written
+	// for every query.
+	levels := logger.NewLevels(zapcore.InfoLevel)
+
+	log, err := logger.New("SALES-API",
+		logger.WithLevels(levels),
+		logger.WithSampling(100, 100, "database"),
+	)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 	defer log.Sync()
 
-	if err := run(log); err != nil {
+	if err := run(log, levels); err != nil {
 		log.Errorw("startup", "ERROR", err)
 		log.Sync()
 		os.Exit(1)
 	}
 }
 
-func run(log *zap.SugaredLogger) error {
+func run(log *zap.SugaredLogger, levels *logger.Levels) error {
 
 	// -------------------------------------------------------------------------
 	// GOMAXPROCS
@@ -62,14 +93,28 @@ func run(log *zap.SugaredLogger) error {
 
 	cfg := struct {
 		conf.Version
+		Log struct {
+			Level  string            `conf:"default:info"`
+			Levels map[string]string `conf:"help:levels by logger name such as database:warn"`
+		}
 		Web struct {
 			ReadTimeout     time.Duration `conf:"default:5s"`
 			WriteTimeout    time.Duration `conf:"default:10s"`
 			IdleTimeout     time.Duration `conf:"default:120s"`
 			ShutdownTimeout time.Duration `conf:"default:20s"`
+			QueryTimeout    time.Duration `conf:"default:5s"`
 			APIHost         string        `conf:"default:0.0.0.0:3000"`
 			DebugHost       string        `conf:"default:0.0.0.0:4000"`
 		}
+		TLS struct {
+			CertFile       string            `conf:"help:certificate served for TLS"`
+			KeyFile        string            `conf:"help:private key of the certificate"`
+			Dir            string            `conf:"help:directory holding tls.crt and tls.key instead of the files"`
+			ReloadInterval time.Duration     `conf:"default:1m"`
+			ClientCAFile   string            `conf:"help:certificate authorities that enable mutual TLS for internal callers"`
+			ClientRoles    map[string]string `conf:"help:roles of client certificate common names such as billing:ADMIN|USER;reports:USER"`
+			HSTSMaxAge     time.Duration     `conf:"default:8760h"`
+		}
 		Auth struct {
 			// KeysFolder string `conf:"default:zarf/keys/"`
 			// ActiveKID  string `conf:"default:54bb2165-71e1-41a6-af3e-7da4a0e1e2c1"`
@@ -81,18 +126,59 @@ func run(log *zap.SugaredLogger) error {
 			Token     string `conf:"default:mytoken,mask"`
 		}
 		DB struct {
-			User         string `conf:"default:postgres"`
-			Password     string `conf:"default:postgres,mask"`
-			Host         string `conf:"default:database-service.sales-system.svc.cluster.local"`
-			Name         string `conf:"default:postgres"`
-			MaxIdleConns int    `conf:"default:2"`
-			MaxOpenConns int    `conf:"default:0"`
-			DisableTLS   bool   `conf:"default:true"`
+			Driver       string        `conf:"default:pgx,help:pgx or postgres"`
+			User         string        `conf:"default:postgres"`
+			Password     string        `conf:"default:postgres,mask"`
+			Host         string        `conf:"default:database-service.sales-system.svc.cluster.local"`
+			Name         string        `conf:"default:postgres"`
+			MaxIdleConns int           `conf:"default:2"`
+			MaxOpenConns int           `conf:"default:0"`
+			DisableTLS   bool          `conf:"default:true"`
+			QueryLog     string        `conf:"default:redacted,help:full|redacted|parameterless"`
+			Replicas     []string      `conf:"help:read replica hosts separated by semicolons"`
+			HealthCheck  time.Duration `conf:"default:5s"`
+		}
+		RateLimit struct {
+			Store          string        `conf:"default:memory,help:memory or postgres"`
+			PublicLimit    int           `conf:"default:60"`
+			PublicPeriod   time.Duration `conf:"default:1m"`
+			UserLimit      int           `conf:"default:600"`
+			UserPeriod     time.Duration `conf:"default:1m"`
+			PruneInterval  time.Duration `conf:"default:10m,help:how often the postgres store removes refilled buckets"`
+			TrustedProxies []string      `conf:"help:proxy IPs or CIDRs separated by ; whose X-Forwarded-For identifies anonymous callers"`
+		}
+		CORS struct {
+			Origins          []string      `conf:"help:allowed origins separated by ; or empty to disable CORS"`
+			PublicOrigins    []string      `conf:"help:allowed origins for public routes separated by ;"`
+			AllowCredentials bool          `conf:"default:false,help:allow credentials from the origins which must not include *"`
+			AllowedHeaders   []string      `conf:"default:Accept;Authorization;Content-Type;Idempotency-Key;If-Modified-Since;If-None-Match"`
+			ExposedHeaders   []string      `conf:"default:ETag;Last-Modified;Retry-After;RateLimit-Limit;RateLimit-Remaining;RateLimit-Reset;Idempotent-Replayed"`
+			MaxAge           time.Duration `conf:"default:10m"`
+		}
+		Idempotency struct {
+			TTL           time.Duration `conf:"default:24h"`
+			LockTimeout   time.Duration `conf:"default:1m,help:how long a key stays claimed by a request that never completes"`
+			PruneInterval time.Duration `conf:"default:1h"`
+		}
+		Report struct {
+			RefreshInterval time.Duration `conf:"default:5m,help:how often the inventory report is recomputed or 0 to disable"`
 		}
 		Tempo struct {
-			ReporterURI string  `conf:"default:tempo.sales-system.svc.cluster.local:4317"`
-			ServiceName string  `conf:"default:sales-api"`
-			Probability float64 `conf:"default:1"` // Shouldn't use a high value in non-developer systems. 0.05 should be enough for most systems. Some might want to have this even lower
+			Exporter         string             `conf:"default:grpc,help:grpc|http|stdout|none"`
+			ReporterURI      string             `conf:"default:tempo.sales-system.svc.cluster.local:4317"`
+			ServiceName      string             `conf:"default:sales-api"`
+			Probability      float64            `conf:"default:1"` // Shouldn't use a high value in non-developer systems. 0.05 should be enough for most systems. Some might want to have this even lower
+			RouteProbability map[string]float64 `conf:"default:/v1/liveness:0;/v1/readiness:0"`
+			BatchTimeout     time.Duration      `conf:"default:5s"`
+			Insecure         bool               `conf:"default:true"`
+			CAFile           string
+			CertFile         string
+			KeyFile          string
+			Attributes       map[string]string
+		}
+		Metrics struct {
+			ReporterURI string        `conf:"help:OTLP gRPC collector endpoint for metrics using the tempo tls settings and empty to disable"`
+			Interval    time.Duration `conf:"default:1m"`
 		}
 	}{
 		Version: conf.Version{
@@ -111,6 +197,13 @@ func run(log *zap.SugaredLogger) error {
 		return fmt.Errorf("parsing config: %w", err)
 	}
 
+	// -------------------------------------------------------------------------
+	// Log Levels
+
+	if err := setLevels(levels, cfg.Log.Level, cfg.Log.Levels); err != nil {
+		return fmt.Errorf("setting log levels: %w", err)
+	}
+
 	// -------------------------------------------------------------------------
 	// App Starting
 
@@ -130,7 +223,14 @@ func run(log *zap.SugaredLogger) error {
 
 	log.Infow("startup", "status", "initializing database support", "host", cfg.DB.Host)
 
+	queryLog, err := querylog.ParseMode(cfg.DB.QueryLog)
+	if err != nil {
+		return fmt.Errorf("parsing query log mode: %w", err)
+	}
+	querylog.SetMode(queryLog)
+
 	db, err := database.Open(database.Config{
+		Driver:       cfg.DB.Driver,
 		User:         cfg.DB.User,
 		Password:     cfg.DB.Password,
 		Host:         cfg.DB.Host,
@@ -138,15 +238,33 @@ func run(log *zap.SugaredLogger) error {
 		MaxIdleConns: cfg.DB.MaxIdleConns,
 		MaxOpenConns: cfg.DB.MaxOpenConns,
 		DisableTLS:   cfg.DB.DisableTLS,
+		Replicas:     cfg.DB.Replicas,
 	})
 	if err != nil {
 		return fmt.Errorf("connecting to db: %w", err)
 	}
+	if err := db.StartHealthCheck(log, cfg.DB.HealthCheck); err != nil {
+		db.Close()
+		return fmt.Errorf("starting database health check: %w", err)
+	}
 	defer func() {
 		log.Infow("shutdown", "status", "stopping database support", "host", cfg.DB.Host)
 		db.Close()
 	}()
 
+	// -------------------------------------------------------------------------
+	// Start Report Refresh Support
+
+	if cfg.Report.RefreshInterval > 0 {
+		log.Infow("startup", "status", "starting report refresh", "interval", cfg.Report.RefreshInterval)
+
+		stop := report.NewCore(log, reportdb.NewStore(log, db)).StartRefresh(cfg.Report.RefreshInterval)
+		defer func() {
+			log.Infow("shutdown", "status", "stopping report refresh")
+			stop()
+		}()
+	}
+
 	// -------------------------------------------------------------------------
 	// Initialize authentication support
 
@@ -167,10 +285,16 @@ func run(log *zap.SugaredLogger) error {
 		return fmt.Errorf("constructing vault: %w", err)
 	}
 
+	certRoles, err := parseCertRoles(cfg.TLS.ClientRoles)
+	if err != nil {
+		return fmt.Errorf("parsing client roles: %w", err)
+	}
+
 	authCfg := auth.Config{
 		Log:       log,
 		DB:        db,
 		KeyLookup: vault,
+		CertRoles: certRoles,
 	}
 
 	auth, err := auth.New(authCfg)
@@ -178,16 +302,77 @@ func run(log *zap.SugaredLogger) error {
 		return fmt.Errorf("constructing auth: %w", err)
 	}
 
+	// -------------------------------------------------------------------------
+	// Initialize rate limiting support
+
+	log.Infow("startup", "status", "initializing rate limiting support", "store", cfg.RateLimit.Store)
+
+	var limitStore ratelimit.Storer
+	switch cfg.RateLimit.Store {
+	case "memory":
+		limitStore = ratelimitmem.NewStore()
+	case "postgres":
+		limitStore = ratelimitdb.NewStore(log, db)
+	default:
+		return fmt.Errorf("unknown rate limit store %q", cfg.RateLimit.Store)
+	}
+
+	limiter := ratelimit.NewLimiter(limitStore)
+
+	// The memory store removes refilled buckets as it goes, the buckets in
+	// the database have to be removed on a schedule.
+	if cfg.RateLimit.Store == "postgres" {
+		if cfg.RateLimit.PruneInterval <= 0 {
+			return fmt.Errorf("rate limit prune interval must be above zero: %s", cfg.RateLimit.PruneInterval)
+		}
+
+		stop := limiter.StartPrune(log, cfg.RateLimit.PruneInterval)
+		defer func() {
+			log.Infow("shutdown", "status", "stopping rate limit pruning")
+			stop()
+		}()
+	}
+
+	// -------------------------------------------------------------------------
+	// Start Idempotency Key Pruning
+
+	switch {
+	case cfg.Idempotency.LockTimeout <= cfg.Web.WriteTimeout:
+		return fmt.Errorf("idempotency lock timeout %s must be longer than the write timeout %s", cfg.Idempotency.LockTimeout, cfg.Web.WriteTimeout)
+	case cfg.Idempotency.PruneInterval <= 0:
+		return fmt.Errorf("idempotency prune interval must be above zero: %s", cfg.Idempotency.PruneInterval)
+	}
+
+	idmStop := idempotency.NewCore(idempotencydb.NewStore(log, db), cfg.Idempotency.TTL, cfg.Idempotency.LockTimeout).StartPrune(log, cfg.Idempotency.PruneInterval)
+	defer func() {
+		log.Infow("shutdown", "status", "stopping idempotency key pruning")
+		idmStop()
+	}()
+
 	// -------------------------------------------------------------------------
 	// Start Tracing Support
 
 	log.Infow("startup", "status", "initializing OT/Tempo tracing support")
 
-	traceProvider, err := startTracing(
-		cfg.Tempo.ServiceName,
-		cfg.Tempo.ReporterURI,
-		cfg.Tempo.Probability,
-	)
+	res := newResource(cfg.Tempo.ServiceName, cfg.Tempo.Attributes)
+
+	var exporterTLS *tls.Config
+	if !cfg.Tempo.Insecure {
+		exporterTLS, err = loadClientTLS(cfg.Tempo.CAFile, cfg.Tempo.CertFile, cfg.Tempo.KeyFile)
+		if err != nil {
+			return fmt.Errorf("loading tracing tls: %w", err)
+		}
+	}
+
+	traceProvider, err := startTracing(tracingConfig{
+		Exporter:         cfg.Tempo.Exporter,
+		ReporterURI:      cfg.Tempo.ReporterURI,
+		Probability:      cfg.Tempo.Probability,
+		RouteProbability: cfg.Tempo.RouteProbability,
+		BatchTimeout:     cfg.Tempo.BatchTimeout,
+		TLS:              exporterTLS,
+		Resource:         res,
+	})
 	if err != nil {
 		return fmt.Errorf("starting tracing: %w", err)
 	}
@@ -196,33 +381,95 @@ func run(log *zap.SugaredLogger) error {
 	tracer := traceProvider.Tracer("service")
 
 	// -------------------------------------------------------------------------
-	// Start Debug Service
-
-	log.Infow("startup", "status", "debug v1 router started", "host", cfg.Web.DebugHost)
-
-	go func() {
-		if err := http.ListenAndServe(cfg.Web.DebugHost, debug.Mux()); err != nil {
-			log.Errorw("shutdown", "status", "debug v1 router closed", "host", cfg.Web.DebugHost, "ERROR", err)
+	// Start Metrics Support
+
+	if cfg.Metrics.ReporterURI != "" {
+		log.Infow("startup", "status", "initializing OT metrics support", "reporter", cfg.Metrics.ReporterURI)
+
+		meterProvider, err := startMetrics(
+			res,
+			cfg.Metrics.ReporterURI,
+			cfg.Metrics.Interval,
+			exporterTLS,
+		)
+		if err != nil {
+			return fmt.Errorf("starting metrics: %w", err)
 		}
-	}()
+		defer meterProvider.Shutdown(context.Background())
+	}
 
 	// -------------------------------------------------------------------------
-	// Start API Service
+	// Initialize API Support
 
 	log.Infow("startup", "status", "initializing V1 API support")
 
+	cors := mid.CORSPolicy{
+		AllowedOrigins:   cfg.CORS.Origins,
+		AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
+		AllowedHeaders:   cfg.CORS.AllowedHeaders,
+		ExposedHeaders:   cfg.CORS.ExposedHeaders,
+		AllowCredentials: cfg.CORS.AllowCredentials,
+		MaxAge:           cfg.CORS.MaxAge,
+	}
+	if err := cors.Validate(); err != nil {
+		return fmt.Errorf("validating cors origins: %w", err)
+	}
+
+	proxies, err := mid.ParseTrustedProxies(cfg.RateLimit.TrustedProxies)
+	if err != nil {
+		return fmt.Errorf("parsing trusted proxies: %w", err)
+	}
+
+	publicCors := mid.CORSPolicy{
+		AllowedOrigins: cfg.CORS.PublicOrigins,
+		AllowedMethods: []string{http.MethodGet},
+		AllowedHeaders: cfg.CORS.AllowedHeaders,
+		ExposedHeaders: cfg.CORS.ExposedHeaders,
+		MaxAge:         cfg.CORS.MaxAge,
+	}
+
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
 
 	apiMux := handlers.APIMux(handlers.APIMuxConfig{
-		Build:    build,
-		Shutdown: shutdown,
-		Log:      log,
-		Auth:     auth,
-		DB:       db,
-		Tracer:   tracer,
+		Build:        build,
+		Shutdown:     shutdown,
+		Log:          log,
+		Auth:         auth,
+		DB:           db,
+		Tracer:       tracer,
+		QueryTimeout: cfg.Web.QueryTimeout,
+		Limiter:      limiter,
+		PublicRate: ratelimit.Rate{
+			Limit:  cfg.RateLimit.PublicLimit,
+			Period: cfg.RateLimit.PublicPeriod,
+		},
+		UserRate: ratelimit.Rate{
+			Limit:  cfg.RateLimit.UserLimit,
+			Period: cfg.RateLimit.UserPeriod,
+		},
+		TrustedProxies:         proxies,
+		IdempotencyTTL:         cfg.Idempotency.TTL,
+		IdempotencyLockTimeout: cfg.Idempotency.LockTimeout,
+		HSTSMaxAge:             cfg.TLS.HSTSMaxAge,
+		CORS:                   cors,
+		PublicCORS:             publicCors,
 	})
 
+	// -------------------------------------------------------------------------
+	// Start Debug Service
+
+	log.Infow("startup", "status", "debug v1 router started", "host", cfg.Web.DebugHost)
+
+	go func() {
+		if err := http.ListenAndServe(cfg.Web.DebugHost, debug.Mux(debug.WithRoutes(apiMux), debug.WithLevels(levels))); err != nil {
+			log.Errorw("shutdown", "status", "debug v1 router closed", "host", cfg.Web.DebugHost, "ERROR", err)
+		}
+	}()
+
+	// -------------------------------------------------------------------------
+	// Start API Service
+
 	api := http.Server{
 		Addr:         cfg.Web.APIHost,
 		Handler:      apiMux,
@@ -232,9 +479,25 @@ func run(log *zap.SugaredLogger) error {
 		ErrorLog:     zap.NewStdLog(log.Desugar()),
 	}
 
+	tlsConfig, tlsStop, err := loadTLS(log, cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.Dir, cfg.TLS.ReloadInterval, cfg.TLS.ClientCAFile)
+	if err != nil {
+		return fmt.Errorf("loading tls: %w", err)
+	}
+	defer func() {
+		log.Infow("shutdown", "status", "stopping tls certificate reload")
+		tlsStop()
+	}()
+	api.TLSConfig = tlsConfig
+
 	serverErrors := make(chan error, 1)
 
 	go func() {
+		if api.TLSConfig != nil {
+			log.Infow("startup", "status", "api router started", "host", api.Addr, "tls", true)
+			serverErrors <- api.ListenAndServeTLS("", "")
+			return
+		}
+
 		log.Infow("startup", "status", "api router started", "host", api.Addr)
 		serverErrors <- api.ListenAndServe()
 	}()
@@ -264,39 +527,191 @@ func run(log *zap.SugaredLogger) error {
 
 // =============================================================================
 
-// startTracing configure open telemetry to be used with Grafana Tempo.
-func startTracing(serviceName string, reporterURI string, probability float64) (*trace.TracerProvider, error) {
+// loadTLS constructs the TLS configuration for the api when a certificate
+// is configured, either as files or as a directory. The certificate is
+// reloaded when it changes on disk until the returned stop function is
+// called. When client certificate authorities are provided, callers can
+// present a client certificate for mutual TLS.
+func loadTLS(log *zap.SugaredLogger, certFile string, keyFile string, dir string, interval time.Duration, clientCAFile string) (*tls.Config, func(), error) {
+	var reloader *certs.Reloader
+	var err error
+
+	switch {
+	case dir != "":
+		reloader, err = certs.NewFromDir(dir)
+	case certFile != "" || keyFile != "":
+		reloader, err = certs.New(certFile, keyFile)
+	default:
+		return nil, func() {}, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// WARNING: The current settings are using defaults which may not be
-	// compatible with your project. Please review the documentation for
-	// opentelemetry.
+	if interval <= 0 {
+		return nil, nil, fmt.Errorf("reload interval must be above zero: %s", interval)
+	}
+
+	tlsConfig := tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if clientCAFile != "" {
+		pool, err := certs.LoadCertPool(clientCAFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("loading client CAs: %w", err)
+		}
+
+		// Client certificates are optional since only internal callers
+		// present one, everyone else uses a token.
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	shutdown := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				reloaded, err := reloader.Reload()
+				if err != nil {
+					log.Errorw("tls", "status", "certificate reload failed", "ERROR", err)
+					continue
+				}
+				if reloaded {
+					log.Infow("tls", "status", "certificate reloaded")
+				}
+			case <-shutdown:
+				return
+			}
+		}
+	}()
+
+	stop := func() {
+		close(shutdown)
+		<-done
+	}
+
+	return &tlsConfig, stop, nil
+}
+
+// parseCertRoles parses the roles configured for each client certificate
+// common name. Multiple roles are separated by a pipe.
+func parseCertRoles(clientRoles map[string]string) (map[string][]user.Role, error) {
+	certRoles := make(map[string][]user.Role, len(clientRoles))
+	for cn, value := range clientRoles {
+		for _, name := range strings.Split(value, "|") {
+			role, err := user.ParseRole(strings.TrimSpace(name))
+			if err != nil {
+				return nil, fmt.Errorf("common name %q: role %q: %w", cn, name, err)
+			}
+			certRoles[cn] = append(certRoles[cn], role)
+		}
+	}
+
+	return certRoles, nil
+}
+
+// setLevels applies the configured default level and the levels by logger
+// name.
+func setLevels(levels *logger.Levels, def string, names map[string]string) error {
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(def)); err != nil {
+		return fmt.Errorf("parsing default level: %w", err)
+	}
+	levels.SetDefault(level)
+
+	for name, lvl := range names {
+		if err := level.UnmarshalText([]byte(lvl)); err != nil {
+			return fmt.Errorf("parsing level for %q: %w", name, err)
+		}
+		levels.Set(name, level)
+	}
+
+	return nil
+}
+
+// tracingConfig represents the settings used to start tracing.
+type tracingConfig struct {
+	Exporter         string
+	ReporterURI      string
+	Probability      float64
+	RouteProbability map[string]float64
+	BatchTimeout     time.Duration
+	TLS              *tls.Config
+	Resource         *resource.Resource
+}
+
+// startTracing configure open telemetry to be used with Grafana Tempo. A nil
+// TLS configuration connects to the reporter without transport security.
+func startTracing(cfg tracingConfig) (*trace.TracerProvider, error) {
+	var exporter trace.SpanExporter
+	var err error
+
+	switch cfg.Exporter {
+	case "grpc":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.ReporterURI)}
+		switch cfg.TLS {
+		case nil:
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		default:
+			opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(cfg.TLS)))
+		}
+		exporter, err = otlptrace.New(context.Background(), otlptracegrpc.NewClient(opts...))
+
+	case "http":
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.ReporterURI)}
+		switch cfg.TLS {
+		case nil:
+			opts = append(opts, otlptracehttp.WithInsecure())
+		default:
+			opts = append(opts, otlptracehttp.WithTLSClientConfig(cfg.TLS))
+		}
+		exporter, err = otlptrace.New(context.Background(), otlptracehttp.NewClient(opts...))
+
+	case "stdout":
+		exporter, err = stdouttrace.New()
+
+	case "none":
+		// Spans are still sampled for the trace ids in the logs.
+
+	default:
+		return nil, fmt.Errorf("unknown exporter %q", cfg.Exporter)
+	}
 
-	exporter, err := otlptrace.New(
-		context.Background(),
-		otlptracegrpc.NewClient(
-			otlptracegrpc.WithInsecure(), // This should be configurable
-			otlptracegrpc.WithEndpoint(reporterURI),
-		),
-	)
 	if err != nil {
 		return nil, fmt.Errorf("creating new exporter: %w", err)
 	}
 
-	traceProvider := trace.NewTracerProvider(
-		trace.WithSampler(trace.TraceIDRatioBased(probability)),
-		trace.WithBatcher(exporter,
-			trace.WithMaxExportBatchSize(trace.DefaultMaxExportBatchSize),
-			trace.WithBatchTimeout(trace.DefaultScheduleDelay*time.Millisecond),
-			trace.WithMaxExportBatchSize(trace.DefaultMaxExportBatchSize),
-		),
-		trace.WithResource(
-			resource.NewWithAttributes(
-				semconv.SchemaURL,
-				semconv.ServiceNameKey.String(serviceName),
-			),
-		),
+	// Requests follow the sampling decision of their parent span unless the
+	// route has its own ratio, such as never sampling the health checks.
+	sampler := web.RouteSampler(
+		trace.ParentBased(trace.TraceIDRatioBased(cfg.Probability)),
+		cfg.RouteProbability,
 	)
 
+	opts := []trace.TracerProviderOption{
+		trace.WithSampler(sampler),
+		trace.WithResource(cfg.Resource),
+	}
+
+	if exporter != nil {
+		opts = append(opts, trace.WithBatcher(exporter,
+			trace.WithMaxExportBatchSize(trace.DefaultMaxExportBatchSize),
+			trace.WithBatchTimeout(cfg.BatchTimeout),
+		))
+	}
+
+	traceProvider := trace.NewTracerProvider(opts...)
+
 	// We must set this provider as the global provider for things to work,
 	// but we pass this provider around the program where needed to collect
 	// our traces.
@@ -304,3 +719,91 @@ func startTracing(serviceName string, reporterURI string, probability float64) (
 
 	return traceProvider, nil
 }
+
+// newResource constructs the resource describing this service to the
+// telemetry backends. It carries the build version, the kubernetes pod
+// information when running in a cluster and any configured attributes.
+func newResource(serviceName string, attributes map[string]string) *resource.Resource {
+	attrs := []attribute.KeyValue{
+		semconv.ServiceNameKey.String(serviceName),
+		semconv.ServiceVersionKey.String(build),
+	}
+
+	k8s := []struct {
+		key attribute.Key
+		env string
+	}{
+		{semconv.K8SNamespaceNameKey, "KUBERNETES_NAMESPACE"},
+		{semconv.K8SPodNameKey, "KUBERNETES_NAME"},
+		{semconv.K8SNodeNameKey, "KUBERNETES_NODE_NAME"},
+		{attribute.Key("k8s.pod.ip"), "KUBERNETES_POD_IP"},
+	}
+	for _, kv := range k8s {
+		if v := os.Getenv(kv.env); v != "" {
+			attrs = append(attrs, kv.key.String(v))
+		}
+	}
+
+	for k, v := range attributes {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...)
+}
+
+// loadClientTLS constructs the TLS configuration used to connect to the
+// telemetry backends. The system roots are used unless a certificate
+// authority file is provided and a client certificate is only presented when
+// both its files are provided.
+func loadClientTLS(caFile string, certFile string, keyFile string) (*tls.Config, error) {
+	cfg := tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if caFile != "" {
+		pool, err := certs.LoadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = pool
+	}
+
+	if certFile != "" && keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return &cfg, nil
+}
+
+// startMetrics configures open telemetry to export the application metrics
+// to an OTLP collector at the specified interval. A nil TLS configuration
+// connects to the collector without transport security.
+func startMetrics(res *resource.Resource, reporterURI string, interval time.Duration, tlsConfig *tls.Config) (*metric.MeterProvider, error) {
+	opts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(reporterURI)}
+	switch tlsConfig {
+	case nil:
+		opts = append(opts, otlpmetricgrpc.WithInsecure())
+	default:
+		opts = append(opts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+	}
+
+	exporter, err := otlpmetricgrpc.New(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating new exporter: %w", err)
+	}
+
+	meterProvider := metric.NewMeterProvider(
+		metric.WithReader(metric.NewPeriodicReader(exporter, metric.WithInterval(interval))),
+		metric.WithResource(res),
+	)
+
+	// The instruments are created from the global provider, which delegates
+	// to this provider once it is set.
+	global.SetMeterProvider(meterProvider)
+
+	return meterProvider, nil
+}
@@ -11,6 +11,7 @@ import (
 	"runtime/debug"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ardanlabs/service/app/services/sales-api/handlers"
 	"github.com/ardanlabs/service/app/services/sales-api/handlers/v1/productgrp"
@@ -52,10 +53,12 @@ func Test_Products(t *testing.T) {
 	shutdown := make(chan os.Signal, 1)
 	tests := ProductTests{
 		app: handlers.APIMux(handlers.APIMuxConfig{
-			Shutdown: shutdown,
-			Log:      test.Log,
-			Auth:     test.Auth,
-			DB:       test.DB,
+			Shutdown:               shutdown,
+			Log:                    test.Log,
+			Auth:                   test.Auth,
+			DB:                     test.DB,
+			IdempotencyTTL:         time.Hour,
+			IdempotencyLockTimeout: time.Minute,
 		}),
 		userToken: test.Token("admin@example.com", "gophers"),
 	}
@@ -63,7 +66,7 @@ func Test_Products(t *testing.T) {
 	// -------------------------------------------------------------------------
 
 	seed := func(ctx context.Context, usrCore *user.Core, prdCore *product.Core) ([]product.Product, error) {
-		usrs, err := usrCore.Query(ctx, user.QueryFilter{}, order.By{Field: user.OrderByName, Direction: order.ASC}, 1, 2)
+		usrs, err := usrCore.Query(ctx, user.QueryFilter{}, order.NewBy(user.OrderByName, order.ASC), 1, 2)
 		if err != nil {
 			return nil, fmt.Errorf("seeding users : %w", err)
 		}
@@ -102,6 +105,7 @@ func Test_Products(t *testing.T) {
 	t.Run("putProduct404", tests.putProduct404())
 	t.Run("crudProducts", tests.crudProduct())
 	t.Run("getProducts200", tests.getProducts200(prds))
+	t.Run("postProductIdempotent", tests.postProductIdempotent())
 }
 
 func (pt *ProductTests) postProduct400() func(t *testing.T) {
@@ -369,6 +373,61 @@ func (pt *ProductTests) postProduct201(t *testing.T) productgrp.AppProduct {
 	return newPrd
 }
 
+// postProductIdempotent validates a retried request replays the response of
+// the first request and a reused key with a different body is rejected.
+func (pt *ProductTests) postProductIdempotent() func(t *testing.T) {
+	return func(t *testing.T) {
+		post := func(name string) *httptest.ResponseRecorder {
+			np := product.NewProduct{
+				Name:     name,
+				Cost:     25,
+				Quantity: 60,
+				UserID:   uuid.MustParse("5cf37266-3473-4006-984f-9325122678b7"),
+			}
+
+			body, err := json.Marshal(&np)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/v1/products", bytes.NewBuffer(body))
+			w := httptest.NewRecorder()
+
+			r.Header.Set("Authorization", "Bearer "+pt.userToken)
+			r.Header.Set("Idempotency-Key", "2f6b1d7e-0f0e-4d0c-9c41-4a0a4b7f6f11")
+			pt.app.ServeHTTP(w, r)
+
+			return w
+		}
+
+		w := post("Comic Books")
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Should receive a status code of 201 for the response : %d", w.Code)
+		}
+		first := w.Body.String()
+
+		w = post("Comic Books")
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Should receive a status code of 201 for the retry : %d", w.Code)
+		}
+
+		if got := w.Header().Get("Idempotent-Replayed"); got != "true" {
+			t.Logf("got: %v", got)
+			t.Logf("exp: %v", "true")
+			t.Error("Should mark the response as replayed")
+		}
+
+		if diff := cmp.Diff(w.Body.String(), first); diff != "" {
+			t.Fatalf("Should get the same product back, Diff:\n%s", diff)
+		}
+
+		w = post("Board Games")
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("Should receive a status code of 422 for a different body : %d", w.Code)
+		}
+	}
+}
+
 // deleteProduct200 validates deleting a product that does exist.
 func (pt *ProductTests) deleteProduct204(t *testing.T, id string) {
 	url := fmt.Sprintf("/v1/products/%s", id)
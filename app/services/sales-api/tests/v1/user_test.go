@@ -66,7 +66,7 @@ func Test_Users(t *testing.T) {
 	// -------------------------------------------------------------------------
 
 	seed := func(ctx context.Context, usrCore *user.Core, prdCore *product.Core) ([]user.User, []product.Product, error) {
-		usrs, err := usrCore.Query(ctx, user.QueryFilter{}, order.By{Field: user.OrderByName, Direction: order.ASC}, 1, 2)
+		usrs, err := usrCore.Query(ctx, user.QueryFilter{}, order.NewBy(user.OrderByName, order.ASC), 1, 2)
 		if err != nil {
 			return nil, nil, fmt.Errorf("seeding users : %w", err)
 		}
@@ -7,7 +7,7 @@ import (
 	"time"
 
 	"github.com/ardanlabs/service/business/data/dbmigrate"
-	database "github.com/ardanlabs/service/business/sys/database/pgx"
+	"github.com/ardanlabs/service/business/sys/database"
 )
 
 // ErrHelp provides context that help was given.
@@ -24,7 +24,7 @@ func Migrate(cfg database.Config) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	if err := dbmigrate.Migrate(ctx, db); err != nil {
+	if err := dbmigrate.Migrate(ctx, db.DB); err != nil {
 		return fmt.Errorf("migrate database: %w", err)
 	}
 
@@ -6,7 +6,7 @@ import (
 	"time"
 
 	"github.com/ardanlabs/service/business/data/dbmigrate"
-	database "github.com/ardanlabs/service/business/sys/database/pgx"
+	"github.com/ardanlabs/service/business/sys/database"
 )
 
 // Seed loads test data into the database.
@@ -20,7 +20,7 @@ func Seed(cfg database.Config) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	if err := dbmigrate.Seed(ctx, db); err != nil {
+	if err := dbmigrate.Seed(ctx, db.DB); err != nil {
 		return fmt.Errorf("seed database: %w", err)
 	}
 
@@ -4,7 +4,10 @@ package event
 import (
 	"context"
 
+	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/web"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.uber.org/zap"
 )
 
@@ -23,18 +26,27 @@ func NewCore(log *zap.SugaredLogger) *Core {
 }
 
 // SendEvent sends event to all handlers registered for the specified event.
+// The dispatch and every handler run in their own span.
 func (c *Core) SendEvent(ctx context.Context, event Event) error {
-	c.log.Infow("sendevent", "trace_id", web.GetTraceID(ctx), "status", "started", "source", event.Source, "type", event.Type, "params", event.RawParams)
-	defer c.log.Infow("sendevent", "trace_id", web.GetTraceID(ctx), "status", "completed")
+	attrs := []attribute.KeyValue{
+		attribute.String("event.source", event.Source),
+		attribute.String("event.type", event.Type),
+	}
+
+	ctx, span := web.AddSpan(ctx, "business.core.event.sendevent", attrs...)
+	defer span.End()
+
+	log := logger.WithContext(ctx, c.log)
+
+	log.Infow("sendevent", "status", "started", "source", event.Source, "type", event.Type, "params", event.RawParams)
+	defer log.Infow("sendevent", "status", "completed")
 
 	if m, ok := c.handlers[event.Source]; ok {
 		if hfs, ok := m[event.Type]; ok {
-			for _, hf := range hfs {
-				c.log.Infow("sendevent", "trace_id", web.GetTraceID(ctx), "status", "sending")
+			for i, hf := range hfs {
+				log.Infow("sendevent", "status", "sending")
 
-				if err := hf(ctx, event); err != nil {
-					c.log.Infof("sendevent", "trace_id", web.GetTraceID(ctx), "ERROR", err)
-				}
+				c.handle(ctx, event, hf, append(attrs, attribute.Int("event.handler", i)))
 			}
 		}
 	}
@@ -42,6 +54,19 @@ func (c *Core) SendEvent(ctx context.Context, event Event) error {
 	return nil
 }
 
+// handle executes a single handler in its own span, recording any error the
+// handler returns since it isn't propagated to the sender.
+func (c *Core) handle(ctx context.Context, event Event, hf HandleFunc, attrs []attribute.KeyValue) {
+	ctx, span := web.AddSpan(ctx, "business.core.event.handler", attrs...)
+	defer span.End()
+
+	if err := hf(ctx, event); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logger.WithContext(ctx, c.log).Infow("sendevent", "ERROR", err)
+	}
+}
+
 // AddHandler add handler to specific event from specific source.
 func (c *Core) AddHandler(source, t string, f HandleFunc) {
 	ss, ok := c.handlers[source]
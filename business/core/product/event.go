@@ -7,7 +7,7 @@ import (
 
 	"github.com/ardanlabs/service/business/core/event"
 	"github.com/ardanlabs/service/business/core/user"
-	"github.com/ardanlabs/service/foundation/web"
+	"github.com/ardanlabs/service/foundation/logger"
 )
 
 func (c *Core) registerEventHandlers(evnCore *event.Core) {
@@ -21,7 +21,7 @@ func (c *Core) handleUserUpdatedEvent(ctx context.Context, ev event.Event) error
 		return fmt.Errorf("expected an encoded %T: %w", params, err)
 	}
 
-	c.log.Infow("user update event", "trace_id", web.GetTraceID(ctx), "user_id", params.UserID, "enabled", params.Enabled)
+	logger.WithContext(ctx, c.log).Infow("user update event", "user_id", params.UserID, "enabled", params.Enabled)
 
 	// Now we can see if this user has been disabled. If they have been, we will
 	// want to disable to mark all these products as deleted. Right now we don't
@@ -0,0 +1,13 @@
+package product
+
+// Set of fields that can be selected when querying products. These are the
+// names that should be used by the application layer.
+const (
+	FieldProdID      = "productid"
+	FieldUserID      = "userid"
+	FieldName        = "name"
+	FieldCost        = "cost"
+	FieldQuantity    = "quantity"
+	FieldDateCreated = "datecreated"
+	FieldDateUpdated = "dateupdated"
+)
@@ -2,17 +2,27 @@ package product
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ardanlabs/service/business/sys/validate"
 	"github.com/google/uuid"
 )
 
-// QueryFilter holds the available fields a query can be filtered on.
+// QueryFilter holds the available fields a query can be filtered on. A
+// product must match every field that is set, any of the values of a list
+// and both ends of a range, which are included.
 type QueryFilter struct {
-	ID       *uuid.UUID `validate:"omitempty"`
-	Name     *string    `validate:"omitempty,min=3"`
-	Cost     *float64   `validate:"omitempty,numeric"`
-	Quantity *int       `validate:"omitempty,numeric"`
+	IDs              []uuid.UUID `validate:"omitempty"`
+	Name             *string     `validate:"omitempty,min=3"`
+	Cost             *float64    `validate:"omitempty,numeric"`
+	MinCost          *float64    `validate:"omitempty,numeric"`
+	MaxCost          *float64    `validate:"omitempty,numeric"`
+	Quantity         *int        `validate:"omitempty,numeric"`
+	MinQuantity      *int        `validate:"omitempty,numeric"`
+	MaxQuantity      *int        `validate:"omitempty,numeric"`
+	StartUpdatedDate *time.Time  `validate:"omitempty"`
+	EndUpdatedDate   *time.Time  `validate:"omitempty"`
+	Search           *string     `validate:"omitempty,min=2"`
 }
 
 // Validate checks the data in the model is considered clean.
@@ -23,9 +33,9 @@ func (qf *QueryFilter) Validate() error {
 	return nil
 }
 
-// WithProductID sets the ID field of the QueryFilter value.
-func (qf *QueryFilter) WithProductID(productID uuid.UUID) {
-	qf.ID = &productID
+// WithProductID sets the IDs field of the QueryFilter value.
+func (qf *QueryFilter) WithProductID(productIDs ...uuid.UUID) {
+	qf.IDs = productIDs
 }
 
 // WithName sets the Name field of the QueryFilter value.
@@ -38,7 +48,46 @@ func (qf *QueryFilter) WithCost(cost float64) {
 	qf.Cost = &cost
 }
 
+// WithMinCost sets the MinCost field of the QueryFilter value.
+func (qf *QueryFilter) WithMinCost(cost float64) {
+	qf.MinCost = &cost
+}
+
+// WithMaxCost sets the MaxCost field of the QueryFilter value.
+func (qf *QueryFilter) WithMaxCost(cost float64) {
+	qf.MaxCost = &cost
+}
+
 // WithQuantity sets the Quantity field of the QueryFilter value.
 func (qf *QueryFilter) WithQuantity(quantity int) {
 	qf.Quantity = &quantity
 }
+
+// WithMinQuantity sets the MinQuantity field of the QueryFilter value.
+func (qf *QueryFilter) WithMinQuantity(quantity int) {
+	qf.MinQuantity = &quantity
+}
+
+// WithMaxQuantity sets the MaxQuantity field of the QueryFilter value.
+func (qf *QueryFilter) WithMaxQuantity(quantity int) {
+	qf.MaxQuantity = &quantity
+}
+
+// WithStartUpdatedDate sets the StartUpdatedDate field of the QueryFilter value.
+func (qf *QueryFilter) WithStartUpdatedDate(startDate time.Time) {
+	d := startDate.UTC()
+	qf.StartUpdatedDate = &d
+}
+
+// WithEndUpdatedDate sets the EndUpdatedDate field of the QueryFilter value.
+func (qf *QueryFilter) WithEndUpdatedDate(endDate time.Time) {
+	d := endDate.UTC()
+	qf.EndUpdatedDate = &d
+}
+
+// WithSearch sets the Search field of the QueryFilter value. The products are
+// matched on the words of their name or on a name similar to the search, and
+// are ranked by relevance.
+func (qf *QueryFilter) WithSearch(search string) {
+	qf.Search = &search
+}
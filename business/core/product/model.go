@@ -15,6 +15,7 @@ type Product struct {
 	Quantity    int
 	DateCreated time.Time
 	DateUpdated time.Time
+	Highlight   string
 }
 
 // NewProduct is what we require from clients when adding a Product.
@@ -27,10 +27,11 @@ var (
 // Storer interface declares the behavior this package needs to perists and
 // retrieve data.
 type Storer interface {
+	WithinTran(ctx context.Context, fn func(ctx context.Context) error) error
 	Create(ctx context.Context, prd Product) error
 	Update(ctx context.Context, prd Product) error
 	Delete(ctx context.Context, prd Product) error
-	Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Product, error)
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int, fields ...string) ([]Product, error)
 	Count(ctx context.Context, filter QueryFilter) (int, error)
 	QueryByID(ctx context.Context, productID uuid.UUID) (Product, error)
 	QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Product, error)
@@ -66,18 +67,15 @@ func NewCore(log *zap.SugaredLogger, evnCore *event.Core, usrCore UserCore, stor
 	return &core
 }
 
+// WithinTran runs the function inside a transaction. Calls to any core made
+// with the context passed to the function take part in the same transaction.
+func (c *Core) WithinTran(ctx context.Context, fn func(ctx context.Context) error) error {
+	return c.storer.WithinTran(ctx, fn)
+}
+
 // Create adds a Product to the database. It returns the created Product with
 // fields like ID and DateCreated populated.
 func (c *Core) Create(ctx context.Context, np NewProduct) (Product, error) {
-	usr, err := c.usrCore.QueryByID(ctx, np.UserID)
-	if err != nil {
-		return Product{}, fmt.Errorf("user.querybyid: %s: %w", np.UserID, err)
-	}
-
-	if !usr.Enabled {
-		return Product{}, ErrInvalidUser
-	}
-
 	now := time.Now()
 
 	prd := Product{
@@ -90,8 +88,26 @@ func (c *Core) Create(ctx context.Context, np NewProduct) (Product, error) {
 		DateUpdated: now,
 	}
 
-	if err := c.storer.Create(ctx, prd); err != nil {
-		return Product{}, fmt.Errorf("create: %w", err)
+	// The user is checked in the same transaction the product is created in.
+	tran := func(ctx context.Context) error {
+		usr, err := c.usrCore.QueryByID(ctx, np.UserID)
+		if err != nil {
+			return fmt.Errorf("user.querybyid: %s: %w", np.UserID, err)
+		}
+
+		if !usr.Enabled {
+			return ErrInvalidUser
+		}
+
+		if err := c.storer.Create(ctx, prd); err != nil {
+			return fmt.Errorf("create: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := c.storer.WithinTran(ctx, tran); err != nil {
+		return Product{}, fmt.Errorf("tran: %w", err)
 	}
 
 	return prd, nil
@@ -127,9 +143,10 @@ func (c *Core) Delete(ctx context.Context, prd Product) error {
 	return nil
 }
 
-// Query gets all Products from the database.
-func (c *Core) Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Product, error) {
-	prds, err := c.storer.Query(ctx, filter, orderBy, pageNumber, rowsPerPage)
+// Query gets all Products from the database. When fields are specified only
+// those fields of the products are populated.
+func (c *Core) Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int, fields ...string) ([]Product, error) {
+	prds, err := c.storer.Query(ctx, filter, orderBy, pageNumber, rowsPerPage, fields...)
 	if err != nil {
 		return nil, fmt.Errorf("query: %w", err)
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/mail"
 	"runtime/debug"
 	"testing"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"github.com/ardanlabs/service/business/data/dbtest"
 	"github.com/ardanlabs/service/foundation/docker"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
 )
 
 var c *docker.Container
@@ -32,6 +34,8 @@ func TestMain(m *testing.M) {
 func Test_Product(t *testing.T) {
 	t.Run("crud", crud)
 	t.Run("paging", paging)
+	t.Run("tran", tran)
+	t.Run("search", search)
 }
 
 // =============================================================================
@@ -286,3 +290,198 @@ func paging(t *testing.T) {
 		t.Fatalf("Should have different product")
 	}
 }
+
+func tran(t *testing.T) {
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	api := test.CoreAPIs
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	nu := user.NewUser{
+		Name:            "Tran Gopher",
+		Email:           mail.Address{Address: "tran@ardanlabs.com"},
+		Roles:           []user.Role{user.RoleUser},
+		Department:      "IT",
+		Password:        "gophers",
+		PasswordConfirm: "gophers",
+	}
+
+	// -------------------------------------------------------------------------
+
+	errRollback := errors.New("rollback")
+
+	var usr user.User
+	var prd product.Product
+	f := func(ctx context.Context) error {
+		var err error
+		if usr, err = api.User.Create(ctx, nu); err != nil {
+			return err
+		}
+
+		np := product.NewProduct{
+			UserID:   usr.ID,
+			Name:     "Tran",
+			Cost:     10,
+			Quantity: 1,
+		}
+		if prd, err = api.Product.Create(ctx, np); err != nil {
+			return err
+		}
+
+		return errRollback
+	}
+
+	if err := api.Product.WithinTran(ctx, f); !errors.Is(err, errRollback) {
+		t.Fatalf("Should get back the error from the transaction : %s", err)
+	}
+
+	if _, err := api.User.QueryByID(ctx, usr.ID); !errors.Is(err, user.ErrNotFound) {
+		t.Fatalf("Should NOT be able to retrieve the user after rollback : %s", err)
+	}
+
+	if _, err := api.Product.QueryByID(ctx, prd.ID); !errors.Is(err, product.ErrNotFound) {
+		t.Fatalf("Should NOT be able to retrieve the product after rollback : %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	f = func(ctx context.Context) error {
+		var err error
+		if usr, err = api.User.Create(ctx, nu); err != nil {
+			return err
+		}
+
+		nested := func(ctx context.Context) error {
+			np := product.NewProduct{
+				UserID:   usr.ID,
+				Name:     "Tran",
+				Cost:     10,
+				Quantity: 1,
+			}
+			if prd, err = api.Product.Create(ctx, np); err != nil {
+				return err
+			}
+
+			return errRollback
+		}
+
+		if err := api.Product.WithinTran(ctx, nested); !errors.Is(err, errRollback) {
+			return fmt.Errorf("nested: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := api.Product.WithinTran(ctx, f); err != nil {
+		t.Fatalf("Should be able to commit the transaction : %s", err)
+	}
+
+	if _, err := api.User.QueryByID(ctx, usr.ID); err != nil {
+		t.Fatalf("Should be able to retrieve the user after commit : %s", err)
+	}
+
+	if _, err := api.Product.QueryByID(ctx, prd.ID); !errors.Is(err, product.ErrNotFound) {
+		t.Fatalf("Should NOT be able to retrieve the product rolled back to the savepoint : %s", err)
+	}
+}
+
+func search(t *testing.T) {
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	api := test.CoreAPIs
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var filter user.QueryFilter
+	filter.WithName("Admin Gopher")
+
+	usrs, err := api.User.Query(ctx, filter, user.DefaultOrderBy, 1, 1)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	for _, name := range []string{"Blue Comic Book", "Red Chair", "Desk <i>Lamp</i> & Shade"} {
+		np := product.NewProduct{
+			UserID:   usrs[0].ID,
+			Name:     name,
+			Cost:     10,
+			Quantity: 1,
+		}
+		if _, err := api.Product.Create(ctx, np); err != nil {
+			t.Fatalf("Seeding error: %s", err)
+		}
+	}
+
+	// -------------------------------------------------------------------------
+
+	tt := []struct {
+		search    string
+		name      string
+		highlight string
+	}{
+		{"comic books", "Blue Comic Book", "Blue <b>Comic</b> <b>Book</b>"},
+		{"Red Chai", "Red Chair", "Red Chair"},
+		{"lamp", "Desk <i>Lamp</i> & Shade", "Desk &lt;i&gt;<b>Lamp</b>&lt;/i&gt; &amp; Shade"},
+	}
+
+	for _, tst := range tt {
+		var filter product.QueryFilter
+		filter.WithSearch(tst.search)
+
+		prds, err := api.Product.Query(ctx, filter, product.DefaultOrderBy, 1, 10)
+		if err != nil {
+			t.Fatalf("Should be able to search products %q : %s", tst.search, err)
+		}
+
+		if len(prds) == 0 || prds[0].Name != tst.name {
+			t.Logf("got: %v", prds)
+			t.Logf("exp: %v", tst.name)
+			t.Fatalf("Should rank %q first for %q", tst.name, tst.search)
+		}
+
+		if prds[0].Highlight != tst.highlight {
+			t.Logf("got: %v", prds[0].Highlight)
+			t.Logf("exp: %v", tst.highlight)
+			t.Errorf("Should highlight the search terms for %q", tst.search)
+		}
+
+		n, err := api.Product.Count(ctx, filter)
+		if err != nil {
+			t.Fatalf("Should be able to count products %q : %s", tst.search, err)
+		}
+
+		if n != len(prds) {
+			t.Logf("got: %v", n)
+			t.Logf("exp: %v", len(prds))
+			t.Errorf("Should count the products found for %q", tst.search)
+		}
+	}
+	// -------------------------------------------------------------------------
+
+	prds, err := api.Product.Query(ctx, product.QueryFilter{}, product.DefaultOrderBy, 1, 10, product.FieldName)
+	if err != nil {
+		t.Fatalf("Should be able to query selected fields : %s", err)
+	}
+
+	if len(prds) == 0 || prds[0].Name == "" || prds[0].ID != uuid.Nil || !prds[0].DateCreated.IsZero() {
+		t.Logf("got: %v", prds)
+		t.Errorf("Should only populate the selected fields.")
+	}
+}
@@ -0,0 +1,39 @@
+package productdb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ardanlabs/service/business/core/product"
+)
+
+const allColumns = "product_id, user_id, name, cost, quantity, date_created, date_updated"
+
+var fieldColumns = map[string]string{
+	product.FieldProdID:      "product_id",
+	product.FieldUserID:      "user_id",
+	product.FieldName:        "name",
+	product.FieldCost:        "cost",
+	product.FieldQuantity:    "quantity",
+	product.FieldDateCreated: "date_created",
+	product.FieldDateUpdated: "date_updated",
+}
+
+// selectColumns returns the columns to select for the specified fields, or
+// every column when no fields are specified.
+func selectColumns(fields []string) (string, error) {
+	if len(fields) == 0 {
+		return allColumns, nil
+	}
+
+	columns := make([]string, len(fields))
+	for i, field := range fields {
+		column, exists := fieldColumns[field]
+		if !exists {
+			return "", fmt.Errorf("field %q does not exist", field)
+		}
+		columns[i] = column
+	}
+
+	return strings.Join(columns, ", "), nil
+}
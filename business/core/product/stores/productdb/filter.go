@@ -6,14 +6,33 @@ import (
 	"strings"
 
 	"github.com/ardanlabs/service/business/core/product"
+	"github.com/ardanlabs/service/business/sys/database/dbarray"
 )
 
+// Set of expressions used by a search. The products are ranked by how well
+// their name matches the words of the search plus how similar it is to the
+// search, which ranks the typos found by the trigram match.
+const (
+	searchQuery     = "websearch_to_tsquery('english', :search)"
+	searchRank      = "ts_rank(search, " + searchQuery + ") + similarity(name, :search)"
+	searchHighlight = "ts_headline('english', " + searchEscaped + ", " + searchQuery + ")"
+)
+
+// searchEscaped escapes the HTML characters of the name before it is
+// highlighted, so the <b> tags added around the matched words are the only
+// markup in the highlight.
+const searchEscaped = "replace(replace(replace(replace(name, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '\"', '&quot;')"
+
 func (s *Store) applyFilter(filter product.QueryFilter, data map[string]interface{}, buf *bytes.Buffer) {
 	var wc []string
 
-	if filter.ID != nil {
-		data["product_id"] = *filter.ID
-		wc = append(wc, "product_id = :product_id")
+	if len(filter.IDs) > 0 {
+		ids := make([]string, len(filter.IDs))
+		for i, id := range filter.IDs {
+			ids[i] = id.String()
+		}
+		data["product_id"] = dbarray.Array(ids)
+		wc = append(wc, "product_id = ANY(:product_id)")
 	}
 
 	if filter.Name != nil {
@@ -26,11 +45,46 @@ func (s *Store) applyFilter(filter product.QueryFilter, data map[string]interfac
 		wc = append(wc, "cost = :cost")
 	}
 
+	if filter.MinCost != nil {
+		data["min_cost"] = *filter.MinCost
+		wc = append(wc, "cost >= :min_cost")
+	}
+
+	if filter.MaxCost != nil {
+		data["max_cost"] = *filter.MaxCost
+		wc = append(wc, "cost <= :max_cost")
+	}
+
 	if filter.Quantity != nil {
 		data["quantity"] = *filter.Quantity
 		wc = append(wc, "quantity = :quantity")
 	}
 
+	if filter.MinQuantity != nil {
+		data["min_quantity"] = *filter.MinQuantity
+		wc = append(wc, "quantity >= :min_quantity")
+	}
+
+	if filter.MaxQuantity != nil {
+		data["max_quantity"] = *filter.MaxQuantity
+		wc = append(wc, "quantity <= :max_quantity")
+	}
+
+	if filter.StartUpdatedDate != nil {
+		data["start_date_updated"] = *filter.StartUpdatedDate
+		wc = append(wc, "date_updated >= :start_date_updated")
+	}
+
+	if filter.EndUpdatedDate != nil {
+		data["end_date_updated"] = *filter.EndUpdatedDate
+		wc = append(wc, "date_updated <= :end_date_updated")
+	}
+
+	if filter.Search != nil {
+		data["search"] = *filter.Search
+		wc = append(wc, "(search @@ "+searchQuery+" OR name % :search)")
+	}
+
 	if len(wc) > 0 {
 		buf.WriteString(" WHERE ")
 		buf.WriteString(strings.Join(wc, " AND "))
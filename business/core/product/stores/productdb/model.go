@@ -16,6 +16,7 @@ type dbProduct struct {
 	UserID      uuid.UUID `db:"user_id"`      // ID of the user who created the product.
 	DateCreated time.Time `db:"date_created"` // When the product was added.
 	DateUpdated time.Time `db:"date_updated"` // When the product record was last modified.
+	Highlight   string    `db:"highlight"`    // Name with the search terms marked, only read by a search.
 }
 
 // =============================================================================
@@ -43,6 +44,7 @@ func toCoreProduct(dbPrd dbProduct) product.Product {
 		Quantity:    dbPrd.Quantity,
 		DateCreated: dbPrd.DateCreated.In(time.Local),
 		DateUpdated: dbPrd.DateUpdated.In(time.Local),
+		Highlight:   dbPrd.Highlight,
 	}
 
 	return prd
@@ -1,8 +1,6 @@
 package productdb
 
 import (
-	"fmt"
-
 	"github.com/ardanlabs/service/business/core/product"
 	"github.com/ardanlabs/service/business/data/order"
 )
@@ -18,10 +16,5 @@ var orderByFields = map[string]string{
 }
 
 func orderByClause(orderBy order.By) (string, error) {
-	by, exists := orderByFields[orderBy.Field]
-	if !exists {
-		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
-	}
-
-	return " ORDER BY " + by + " " + orderBy.Direction, nil
+	return order.Clause(orderBy, orderByFields, "product_id")
 }
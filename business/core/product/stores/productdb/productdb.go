@@ -9,26 +9,31 @@ import (
 
 	"github.com/ardanlabs/service/business/core/product"
 	"github.com/ardanlabs/service/business/data/order"
-	database "github.com/ardanlabs/service/business/sys/database/pgx"
+	"github.com/ardanlabs/service/business/sys/database"
 	"github.com/google/uuid"
-	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
 )
 
 // Store manages the set of APIs for product database access.
 type Store struct {
 	log *zap.SugaredLogger
-	db  sqlx.ExtContext
+	db  *database.DB
 }
 
 // NewStore constructs the api for data access.
-func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+func NewStore(log *zap.SugaredLogger, db *database.DB) *Store {
 	return &Store{
 		log: log,
 		db:  db,
 	}
 }
 
+// WithinTran runs passed function and do commit/rollback at the end. The
+// transaction is carried by the context passed to the function.
+func (s *Store) WithinTran(ctx context.Context, fn func(ctx context.Context) error) error {
+	return database.WithinTran(ctx, s.log, s.db, fn)
+}
+
 // Create adds a Product to the database. It returns the created Product with
 // fields like ID and DateCreated populated.
 func (s *Store) Create(ctx context.Context, prd product.Product) error {
@@ -87,20 +92,24 @@ func (s *Store) Delete(ctx context.Context, prd product.Product) error {
 	return nil
 }
 
-// Query gets all Products from the database.
-func (s *Store) Query(ctx context.Context, filter product.QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]product.Product, error) {
+// Query gets all Products from the database. When fields are specified only
+// their columns are selected and the other fields are left zero valued.
+func (s *Store) Query(ctx context.Context, filter product.QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int, fields ...string) ([]product.Product, error) {
 	data := map[string]interface{}{
 		"offset":        (pageNumber - 1) * rowsPerPage,
 		"rows_per_page": rowsPerPage,
 	}
 
-	const q = `
-	SELECT
-		*
-	FROM
-		products`
+	columns, err := selectColumns(fields)
+	if err != nil {
+		return nil, err
+	}
 
-	buf := bytes.NewBufferString(q)
+	buf := bytes.NewBufferString("SELECT " + columns)
+	if filter.Search != nil {
+		buf.WriteString(", " + searchHighlight + " AS highlight")
+	}
+	buf.WriteString(" FROM products")
 	s.applyFilter(filter, data, buf)
 
 	orderByClause, err := orderByClause(orderBy)
@@ -108,6 +117,10 @@ func (s *Store) Query(ctx context.Context, filter product.QueryFilter, orderBy o
 		return nil, err
 	}
 
+	buf.WriteString(" ORDER BY ")
+	if filter.Search != nil {
+		buf.WriteString(searchRank + " DESC, ")
+	}
 	buf.WriteString(orderByClause)
 	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
 
@@ -154,7 +167,7 @@ func (s *Store) QueryByID(ctx context.Context, productID uuid.UUID) (product.Pro
 
 	const q = `
 	SELECT
-		*
+		product_id, user_id, name, cost, quantity, date_created, date_updated
 	FROM
 		products
 	WHERE
@@ -181,7 +194,7 @@ func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]product.
 
 	const q = `
 	SELECT
-		*
+		product_id, user_id, name, cost, quantity, date_created, date_updated
 	FROM
 		products
 	WHERE
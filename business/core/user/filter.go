@@ -9,13 +9,22 @@ import (
 	"github.com/google/uuid"
 )
 
-// QueryFilter holds the available fields a query can be filtered on.
+// QueryFilter holds the available fields a query can be filtered on. A user
+// must match every field that is set, any of the values of a list and both
+// ends of a range, which are included. A user matches a list of roles when it
+// has any of them.
 type QueryFilter struct {
-	ID               *uuid.UUID    `validate:"omitempty"`
+	IDs              []uuid.UUID   `validate:"omitempty"`
 	Name             *string       `validate:"omitempty,min=3"`
 	Email            *mail.Address `validate:"omitempty"`
+	Roles            []Role        `validate:"omitempty"`
+	Department       *string       `validate:"omitempty"`
+	Enabled          *bool         `validate:"omitempty"`
 	StartCreatedDate *time.Time    `validate:"omitempty"`
 	EndCreatedDate   *time.Time    `validate:"omitempty"`
+	StartUpdatedDate *time.Time    `validate:"omitempty"`
+	EndUpdatedDate   *time.Time    `validate:"omitempty"`
+	Search           *string       `validate:"omitempty,min=2"`
 }
 
 // Validate checks the data in the model is considered clean.
@@ -26,9 +35,9 @@ func (qf *QueryFilter) Validate() error {
 	return nil
 }
 
-// WithUserID sets the ID field of the QueryFilter value.
-func (qf *QueryFilter) WithUserID(userID uuid.UUID) {
-	qf.ID = &userID
+// WithUserID sets the IDs field of the QueryFilter value.
+func (qf *QueryFilter) WithUserID(userIDs ...uuid.UUID) {
+	qf.IDs = userIDs
 }
 
 // WithName sets the Name field of the QueryFilter value.
@@ -41,6 +50,21 @@ func (qf *QueryFilter) WithEmail(email mail.Address) {
 	qf.Email = &email
 }
 
+// WithRoles sets the Roles field of the QueryFilter value.
+func (qf *QueryFilter) WithRoles(roles ...Role) {
+	qf.Roles = roles
+}
+
+// WithDepartment sets the Department field of the QueryFilter value.
+func (qf *QueryFilter) WithDepartment(department string) {
+	qf.Department = &department
+}
+
+// WithEnabled sets the Enabled field of the QueryFilter value.
+func (qf *QueryFilter) WithEnabled(enabled bool) {
+	qf.Enabled = &enabled
+}
+
 // WithStartDateCreated sets the DateCreated field of the QueryFilter value.
 func (qf *QueryFilter) WithStartDateCreated(startDate time.Time) {
 	d := startDate.UTC()
@@ -52,3 +76,22 @@ func (qf *QueryFilter) WithEndCreatedDate(endDate time.Time) {
 	d := endDate.UTC()
 	qf.EndCreatedDate = &d
 }
+
+// WithStartUpdatedDate sets the StartUpdatedDate field of the QueryFilter value.
+func (qf *QueryFilter) WithStartUpdatedDate(startDate time.Time) {
+	d := startDate.UTC()
+	qf.StartUpdatedDate = &d
+}
+
+// WithEndUpdatedDate sets the EndUpdatedDate field of the QueryFilter value.
+func (qf *QueryFilter) WithEndUpdatedDate(endDate time.Time) {
+	d := endDate.UTC()
+	qf.EndUpdatedDate = &d
+}
+
+// WithSearch sets the Search field of the QueryFilter value. The users are
+// matched on the words of their name and department or on a name similar to
+// the search, and are ranked by relevance.
+func (qf *QueryFilter) WithSearch(search string) {
+	qf.Search = &search
+}
@@ -19,6 +19,7 @@ type User struct {
 	Enabled      bool
 	DateCreated  time.Time
 	DateUpdated  time.Time
+	Highlight    string
 }
 
 // NewUser contains information needed to create a new user.
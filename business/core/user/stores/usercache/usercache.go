@@ -8,6 +8,7 @@ import (
 
 	"github.com/ardanlabs/service/business/core/user"
 	"github.com/ardanlabs/service/business/data/order"
+	"github.com/ardanlabs/service/business/sys/database"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -30,7 +31,7 @@ func NewStore(log *zap.SugaredLogger, storer user.Storer) *Store {
 }
 
 // WithinTran runs passed function and do commit/rollback at the end.
-func (s *Store) WithinTran(ctx context.Context, fn func(s user.Storer) error) error {
+func (s *Store) WithinTran(ctx context.Context, fn func(ctx context.Context) error) error {
 	return s.storer.WithinTran(ctx, fn)
 }
 
@@ -40,7 +41,7 @@ func (s *Store) Create(ctx context.Context, usr user.User) error {
 		return err
 	}
 
-	s.writeCache(usr)
+	s.writeCacheOutsideTran(ctx, usr)
 
 	return nil
 }
@@ -51,7 +52,7 @@ func (s *Store) Update(ctx context.Context, usr user.User) error {
 		return err
 	}
 
-	s.writeCache(usr)
+	s.writeCacheOutsideTran(ctx, usr)
 
 	return nil
 }
@@ -79,9 +80,10 @@ func (s *Store) Count(ctx context.Context, filter user.QueryFilter) (int, error)
 
 // QueryByID gets the specified user from the database.
 func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (user.User, error) {
-	cachedUsr, ok := s.readCache(userID.String())
-	if ok {
-		return cachedUsr, nil
+	if !database.InTran(ctx) {
+		if cachedUsr, ok := s.readCache(userID.String()); ok {
+			return cachedUsr, nil
+		}
 	}
 
 	usr, err := s.storer.QueryByID(ctx, userID)
@@ -89,7 +91,7 @@ func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (user.User, err
 		return user.User{}, err
 	}
 
-	s.writeCache(usr)
+	s.writeCacheOutsideTran(ctx, usr)
 
 	return usr, nil
 }
@@ -106,9 +108,10 @@ func (s *Store) QueryByIDs(ctx context.Context, userIDs []uuid.UUID) ([]user.Use
 
 // QueryByEmail gets the specified user from the database by email.
 func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (user.User, error) {
-	cachedUsr, ok := s.readCache(email.Address)
-	if ok {
-		return cachedUsr, nil
+	if !database.InTran(ctx) {
+		if cachedUsr, ok := s.readCache(email.Address); ok {
+			return cachedUsr, nil
+		}
 	}
 
 	usr, err := s.storer.QueryByEmail(ctx, email)
@@ -116,7 +119,7 @@ func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (user.User
 		return user.User{}, err
 	}
 
-	s.writeCache(usr)
+	s.writeCacheOutsideTran(ctx, usr)
 
 	return usr, nil
 }
@@ -145,6 +148,19 @@ func (s *Store) writeCache(usr user.User) {
 	s.cache[usr.Email.Address] = &usr
 }
 
+// writeCacheOutsideTran writes the user to the cache unless the context
+// carries a transaction. Inside a transaction the user is removed instead,
+// since the change isn't visible to others until commit and may be rolled
+// back. The next read after the transaction loads the user again.
+func (s *Store) writeCacheOutsideTran(ctx context.Context, usr user.User) {
+	if database.InTran(ctx) {
+		s.deleteCache(usr)
+		return
+	}
+
+	s.writeCache(usr)
+}
+
 // deleteCache performs a safe removal from the cache for the specified user.
 func (s *Store) deleteCache(usr user.User) {
 	s.mu.Lock()
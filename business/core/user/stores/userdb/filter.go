@@ -6,14 +6,33 @@ import (
 	"strings"
 
 	"github.com/ardanlabs/service/business/core/user"
+	"github.com/ardanlabs/service/business/sys/database/dbarray"
 )
 
+// Set of expressions used by a search. The users are ranked by how well their
+// name and department match the words of the search plus how similar their
+// name is to the search, which ranks the typos found by the trigram match.
+const (
+	searchQuery     = "websearch_to_tsquery('english', :search)"
+	searchRank      = "ts_rank(search, " + searchQuery + ") + similarity(name, :search)"
+	searchHighlight = "ts_headline('english', " + searchEscaped + ", " + searchQuery + ")"
+)
+
+// searchEscaped escapes the HTML characters of the name before it is
+// highlighted, so the <b> tags added around the matched words are the only
+// markup in the highlight.
+const searchEscaped = "replace(replace(replace(replace(name, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '\"', '&quot;')"
+
 func (s *Store) applyFilter(filter user.QueryFilter, data map[string]interface{}, buf *bytes.Buffer) {
 	var wc []string
 
-	if filter.ID != nil {
-		data["user_id"] = *filter.ID
-		wc = append(wc, "user_id = :user_id")
+	if len(filter.IDs) > 0 {
+		ids := make([]string, len(filter.IDs))
+		for i, id := range filter.IDs {
+			ids[i] = id.String()
+		}
+		data["user_id"] = dbarray.Array(ids)
+		wc = append(wc, "user_id = ANY(:user_id)")
 	}
 
 	if filter.Name != nil {
@@ -26,6 +45,25 @@ func (s *Store) applyFilter(filter user.QueryFilter, data map[string]interface{}
 		wc = append(wc, "email = :email")
 	}
 
+	if len(filter.Roles) > 0 {
+		roles := make([]string, len(filter.Roles))
+		for i, role := range filter.Roles {
+			roles[i] = role.Name()
+		}
+		data["roles"] = dbarray.Array(roles)
+		wc = append(wc, "roles && :roles")
+	}
+
+	if filter.Department != nil {
+		data["department"] = *filter.Department
+		wc = append(wc, "department = :department")
+	}
+
+	if filter.Enabled != nil {
+		data["enabled"] = *filter.Enabled
+		wc = append(wc, "enabled = :enabled")
+	}
+
 	if filter.StartCreatedDate != nil {
 		data["start_date_created"] = *filter.StartCreatedDate
 		wc = append(wc, "date_created >= :start_date_created")
@@ -36,6 +74,21 @@ func (s *Store) applyFilter(filter user.QueryFilter, data map[string]interface{}
 		wc = append(wc, "date_created <= :end_date_created")
 	}
 
+	if filter.StartUpdatedDate != nil {
+		data["start_date_updated"] = *filter.StartUpdatedDate
+		wc = append(wc, "date_updated >= :start_date_updated")
+	}
+
+	if filter.EndUpdatedDate != nil {
+		data["end_date_updated"] = *filter.EndUpdatedDate
+		wc = append(wc, "date_updated <= :end_date_updated")
+	}
+
+	if filter.Search != nil {
+		data["search"] = *filter.Search
+		wc = append(wc, "(search @@ "+searchQuery+" OR name % :search)")
+	}
+
 	if len(wc) > 0 {
 		buf.WriteString(" WHERE ")
 		buf.WriteString(strings.Join(wc, " AND "))
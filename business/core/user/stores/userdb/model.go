@@ -6,7 +6,7 @@ import (
 	"time"
 
 	"github.com/ardanlabs/service/business/core/user"
-	"github.com/ardanlabs/service/business/sys/database/pgx/dbarray"
+	"github.com/ardanlabs/service/business/sys/database/dbarray"
 	"github.com/google/uuid"
 )
 
@@ -15,13 +15,14 @@ import (
 type dbUser struct {
 	ID           uuid.UUID      `db:"user_id"`
 	Name         string         `db:"name"`
-	Email        string         `db:"email"`
+	Email        string         `db:"email,sensitive"`
 	Roles        dbarray.String `db:"roles"`
-	PasswordHash []byte         `db:"password_hash"`
+	PasswordHash []byte         `db:"password_hash,sensitive"`
 	Enabled      bool           `db:"enabled"`
 	Department   sql.NullString `db:"department"`
 	DateCreated  time.Time      `db:"date_created"`
 	DateUpdated  time.Time      `db:"date_updated"`
+	Highlight    string         `db:"highlight"`
 }
 
 func toDBUser(usr user.User) dbUser {
@@ -66,6 +67,7 @@ func toCoreUser(dbUsr dbUser) user.User {
 		Department:   dbUsr.Department.String,
 		DateCreated:  dbUsr.DateCreated.In(time.Local),
 		DateUpdated:  dbUsr.DateUpdated.In(time.Local),
+		Highlight:    dbUsr.Highlight,
 	}
 
 	return usr
@@ -12,44 +12,37 @@ import (
 
 	"github.com/ardanlabs/service/business/core/user"
 	"github.com/ardanlabs/service/business/data/order"
-	database "github.com/ardanlabs/service/business/sys/database/pgx"
-	"github.com/ardanlabs/service/business/sys/database/pgx/dbarray"
+	"github.com/ardanlabs/service/business/sys/database"
+	"github.com/ardanlabs/service/business/sys/database/dbarray"
+	"github.com/ardanlabs/service/business/sys/database/querylog"
 	"github.com/google/uuid"
-	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
 )
 
+// The email is bound from a map by the query filter, where it can't be marked
+// sensitive with a tag.
+func init() {
+	querylog.Sensitive("email")
+}
+
 // Store manages the set of APIs for user database access.
 type Store struct {
-	log    *zap.SugaredLogger
-	db     sqlx.ExtContext
-	inTran bool
+	log *zap.SugaredLogger
+	db  *database.DB
 }
 
 // NewStore constructs the api for data access.
-func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+func NewStore(log *zap.SugaredLogger, db *database.DB) *Store {
 	return &Store{
 		log: log,
 		db:  db,
 	}
 }
 
-// WithinTran runs passed function and do commit/rollback at the end.
-func (s *Store) WithinTran(ctx context.Context, fn func(s user.Storer) error) error {
-	if s.inTran {
-		return fn(s)
-	}
-
-	f := func(tx *sqlx.Tx) error {
-		s := &Store{
-			log:    s.log,
-			db:     tx,
-			inTran: true,
-		}
-		return fn(s)
-	}
-
-	return database.WithinTran(ctx, s.log, s.db.(*sqlx.DB), f)
+// WithinTran runs passed function and do commit/rollback at the end. The
+// transaction is carried by the context passed to the function.
+func (s *Store) WithinTran(ctx context.Context, fn func(ctx context.Context) error) error {
+	return database.WithinTran(ctx, s.log, s.db, fn)
 }
 
 // Create inserts a new user into the database.
@@ -125,11 +118,13 @@ func (s *Store) Query(ctx context.Context, filter user.QueryFilter, orderBy orde
 
 	const q = `
 	SELECT
-		*
-	FROM
-		users`
+		user_id, name, email, roles, password_hash, department, enabled, date_created, date_updated`
 
 	buf := bytes.NewBufferString(q)
+	if filter.Search != nil {
+		buf.WriteString(", " + searchHighlight + " AS highlight")
+	}
+	buf.WriteString(" FROM users")
 	s.applyFilter(filter, data, buf)
 
 	orderByClause, err := orderByClause(orderBy)
@@ -137,6 +132,10 @@ func (s *Store) Query(ctx context.Context, filter user.QueryFilter, orderBy orde
 		return nil, err
 	}
 
+	buf.WriteString(" ORDER BY ")
+	if filter.Search != nil {
+		buf.WriteString(searchRank + " DESC, ")
+	}
 	buf.WriteString(orderByClause)
 	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
 
@@ -181,7 +180,7 @@ func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (user.User, err
 
 	const q = `
 	SELECT
-		*
+		user_id, name, email, roles, password_hash, department, enabled, date_created, date_updated
 	FROM
 		users
 	WHERE 
@@ -216,7 +215,7 @@ func (s *Store) QueryByIDs(ctx context.Context, userIDs []uuid.UUID) ([]user.Use
 
 	const q = `
 	SELECT
-		*
+		user_id, name, email, roles, password_hash, department, enabled, date_created, date_updated
 	FROM
 		users
 	WHERE
@@ -243,7 +242,7 @@ func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (user.User
 
 	const q = `
 	SELECT
-		*
+		user_id, name, email, roles, password_hash, department, enabled, date_created, date_updated
 	FROM
 		users
 	WHERE
@@ -28,7 +28,7 @@ var (
 // Storer interface declares the behavior this package needs to perists and
 // retrieve data.
 type Storer interface {
-	WithinTran(ctx context.Context, fn func(s Storer) error) error
+	WithinTran(ctx context.Context, fn func(ctx context.Context) error) error
 	Create(ctx context.Context, usr User) error
 	Update(ctx context.Context, usr User) error
 	Delete(ctx context.Context, usr User) error
@@ -55,6 +55,12 @@ func NewCore(evnCore *event.Core, storer Storer) *Core {
 	}
 }
 
+// WithinTran runs the function inside a transaction. Calls to any core made
+// with the context passed to the function take part in the same transaction.
+func (c *Core) WithinTran(ctx context.Context, fn func(ctx context.Context) error) error {
+	return c.storer.WithinTran(ctx, fn)
+}
+
 // Create inserts a new user into the database.
 func (c *Core) Create(ctx context.Context, nu NewUser) (User, error) {
 	hash, err := bcrypt.GenerateFromPassword([]byte(nu.Password), bcrypt.DefaultCost)
@@ -77,8 +83,8 @@ func (c *Core) Create(ctx context.Context, nu NewUser) (User, error) {
 	}
 
 	// This provides an example of how to execute a transaction if required.
-	tran := func(s Storer) error {
-		if err := s.Create(ctx, usr); err != nil {
+	tran := func(ctx context.Context) error {
+		if err := c.storer.Create(ctx, usr); err != nil {
 			return fmt.Errorf("create: %w", err)
 		}
 		return nil
@@ -40,7 +40,7 @@ func Test_User(t *testing.T) {
 
 func crud(t *testing.T) {
 	seed := func(ctx context.Context, usrCore *user.Core, prdCore *product.Core) ([]user.User, error) {
-		usrs, err := usrCore.Query(ctx, user.QueryFilter{}, order.By{Field: user.OrderByName, Direction: order.ASC}, 1, 1)
+		usrs, err := usrCore.Query(ctx, user.QueryFilter{}, order.NewBy(user.OrderByName, order.ASC), 1, 1)
 		if err != nil {
 			return nil, fmt.Errorf("seeding users : %w", err)
 		}
@@ -0,0 +1,83 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/sys/validate"
+	"github.com/google/uuid"
+)
+
+// ActivityFilter holds the available fields the product activity can be
+// filtered on.
+type ActivityFilter struct {
+	UserID    *uuid.UUID `validate:"omitempty"`
+	StartDate *time.Time `validate:"omitempty"`
+	EndDate   *time.Time `validate:"omitempty"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (af *ActivityFilter) Validate() error {
+	if err := validate.Check(af); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	if af.StartDate != nil && af.EndDate != nil && af.EndDate.Before(*af.StartDate) {
+		return validate.NewFieldsError("end_date", errors.New("must not be before start_date"))
+	}
+
+	return nil
+}
+
+// WithUserID sets the UserID field of the ActivityFilter value.
+func (af *ActivityFilter) WithUserID(userID uuid.UUID) {
+	af.UserID = &userID
+}
+
+// WithStartDate sets the StartDate field of the ActivityFilter value.
+func (af *ActivityFilter) WithStartDate(startDate time.Time) {
+	d := startDate.UTC()
+	af.StartDate = &d
+}
+
+// WithEndDate sets the EndDate field of the ActivityFilter value.
+func (af *ActivityFilter) WithEndDate(endDate time.Time) {
+	d := endDate.UTC()
+	af.EndDate = &d
+}
+
+// =============================================================================
+
+// InventoryFilter holds the available fields the inventory can be filtered on.
+type InventoryFilter struct {
+	UserID     *uuid.UUID `validate:"omitempty"`
+	Department *string    `validate:"omitempty"`
+
+	// IncludeEmpty includes the users who have no products, which are
+	// left out by default.
+	IncludeEmpty *bool
+}
+
+// Validate checks the data in the model is considered clean.
+func (inf *InventoryFilter) Validate() error {
+	if err := validate.Check(inf); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	return nil
+}
+
+// WithUserID sets the UserID field of the InventoryFilter value.
+func (inf *InventoryFilter) WithUserID(userID uuid.UUID) {
+	inf.UserID = &userID
+}
+
+// WithDepartment sets the Department field of the InventoryFilter value.
+func (inf *InventoryFilter) WithDepartment(department string) {
+	inf.Department = &department
+}
+
+// WithIncludeEmpty sets the IncludeEmpty field of the InventoryFilter value.
+func (inf *InventoryFilter) WithIncludeEmpty(includeEmpty bool) {
+	inf.IncludeEmpty = &includeEmpty
+}
@@ -0,0 +1,37 @@
+package report
+
+import "errors"
+
+// Set of possible intervals the products can be bucketed by.
+var (
+	IntervalDay   = Interval{"day"}
+	IntervalWeek  = Interval{"week"}
+	IntervalMonth = Interval{"month"}
+)
+
+// Set of known intervals.
+var intervals = map[string]Interval{
+	IntervalDay.name:   IntervalDay,
+	IntervalWeek.name:  IntervalWeek,
+	IntervalMonth.name: IntervalMonth,
+}
+
+// Interval represents the size of the time buckets of a report.
+type Interval struct {
+	name string
+}
+
+// ParseInterval parses the string value and returns an interval if one exists.
+func ParseInterval(value string) (Interval, error) {
+	interval, exists := intervals[value]
+	if !exists {
+		return Interval{}, errors.New("invalid interval, valid intervals are: day, week, month")
+	}
+
+	return interval, nil
+}
+
+// Name returns the name of the interval.
+func (i Interval) Name() string {
+	return i.name
+}
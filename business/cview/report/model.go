@@ -0,0 +1,26 @@
+package report
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Activity represents the products created within one time bucket.
+type Activity struct {
+	Start      time.Time
+	TotalCount int
+	TotalValue float64
+}
+
+// Inventory represents the value of the products held by an individual user.
+// Products have no category of their own, so the department of their owner
+// is used to categorize them.
+type Inventory struct {
+	UserID        uuid.UUID
+	UserName      string
+	Department    string
+	TotalCount    int
+	TotalQuantity int
+	TotalValue    float64
+}
@@ -0,0 +1,18 @@
+package report
+
+import "github.com/ardanlabs/service/business/data/order"
+
+// DefaultInventoryOrderBy represents the default way the inventory is sorted,
+// the users holding the most value first.
+var DefaultInventoryOrderBy = order.NewBy(OrderByTotalValue, order.DESC)
+
+// Set of fields that the inventory can be ordered by. These are the names
+// that should be used by the application layer.
+const (
+	OrderByUserID        = "userid"
+	OrderByUserName      = "userName"
+	OrderByDepartment    = "department"
+	OrderByTotalCount    = "totalCount"
+	OrderByTotalQuantity = "totalQuantity"
+	OrderByTotalValue    = "totalValue"
+)
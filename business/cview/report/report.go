@@ -0,0 +1,127 @@
+// Package report provides an example of a core business API that aggregates
+// the products and their owners for reporting.
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/data/order"
+	"go.uber.org/zap"
+)
+
+// ErrRefreshing is returned when the inventory is already being refreshed,
+// possibly by another instance of the service.
+var ErrRefreshing = errors.New("inventory refresh in progress")
+
+// Storer interface declares the behavior this package needs to retrieve
+// the aggregated data.
+type Storer interface {
+	QueryActivity(ctx context.Context, filter ActivityFilter, interval Interval) ([]Activity, error)
+	QueryInventory(ctx context.Context, filter InventoryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Inventory, error)
+	CountInventory(ctx context.Context, filter InventoryFilter) (int, error)
+	RefreshInventory(ctx context.Context) error
+}
+
+// =============================================================================
+
+// Core manages the set of APIs for report access.
+type Core struct {
+	log    *zap.SugaredLogger
+	storer Storer
+}
+
+// NewCore constructs a core for report api access.
+func NewCore(log *zap.SugaredLogger, storer Storer) *Core {
+	return &Core{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// QueryActivity retrieves the number and value of the products created in
+// each time bucket of the specified interval, oldest first.
+func (c *Core) QueryActivity(ctx context.Context, filter ActivityFilter, interval Interval) ([]Activity, error) {
+	acts, err := c.storer.QueryActivity(ctx, filter, interval)
+	if err != nil {
+		return nil, fmt.Errorf("query: interval[%s]: %w", interval.Name(), err)
+	}
+
+	return acts, nil
+}
+
+// QueryInventory retrieves a page of the value of the products held by each
+// user. The inventory is read from a snapshot, so it trails the products by
+// up to the refresh interval.
+func (c *Core) QueryInventory(ctx context.Context, filter InventoryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Inventory, error) {
+	invs, err := c.storer.QueryInventory(ctx, filter, orderBy, pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return invs, nil
+}
+
+// CountInventory returns the total number of users in the inventory.
+func (c *Core) CountInventory(ctx context.Context, filter InventoryFilter) (int, error) {
+	return c.storer.CountInventory(ctx, filter)
+}
+
+// RefreshInventory rebuilds the inventory snapshot from the products. Only
+// one refresh runs at a time across every instance of the service, the
+// others fail with ErrRefreshing.
+func (c *Core) RefreshInventory(ctx context.Context) error {
+	if err := c.storer.RefreshInventory(ctx); err != nil {
+		return fmt.Errorf("refresh: %w", err)
+	}
+
+	return nil
+}
+
+// StartRefresh refreshes the inventory snapshot at the specified interval
+// until the returned function is called. Each refresh is given the interval
+// to complete.
+func (c *Core) StartRefresh(interval time.Duration) (stop func()) {
+	shutdown := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.refresh(interval)
+			case <-shutdown:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(shutdown)
+		<-done
+	}
+}
+
+func (c *Core) refresh(timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	if err := c.RefreshInventory(ctx); err != nil {
+		if errors.Is(err, ErrRefreshing) {
+			c.log.Infow("report", "status", "inventory refresh skipped", "reason", err)
+			return
+		}
+		c.log.Errorw("report", "status", "inventory refresh failed", "ERROR", err)
+		return
+	}
+
+	c.log.Infow("report", "status", "inventory refreshed", "duration", time.Since(start))
+}
@@ -0,0 +1,194 @@
+package report_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/core/product"
+	"github.com/ardanlabs/service/business/core/user"
+	"github.com/ardanlabs/service/business/cview/report"
+	"github.com/ardanlabs/service/business/data/dbtest"
+	"github.com/ardanlabs/service/business/data/order"
+	"github.com/ardanlabs/service/business/sys/database"
+	"github.com/ardanlabs/service/foundation/docker"
+)
+
+var c *docker.Container
+
+func TestMain(m *testing.M) {
+	var err error
+	c, err = dbtest.StartDB()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer dbtest.StopDB(c)
+
+	m.Run()
+}
+
+func Test_Report(t *testing.T) {
+	t.Run("aggregates", aggregates)
+	t.Run("refreshlock", refreshLock)
+}
+
+// =============================================================================
+
+func aggregates(t *testing.T) {
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	api := test.CoreAPIs
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Log("Go seeding ...")
+
+	usrs, err := api.User.Query(ctx, user.QueryFilter{}, order.NewBy(user.OrderByName, order.ASC), 1, 1)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	prds, err := product.TestGenerateSeedProducts(3, api.Product, usrs[0].ID)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	var totalValue float64
+	for _, prd := range prds {
+		totalValue += prd.Cost * float64(prd.Quantity)
+	}
+
+	// -------------------------------------------------------------------------
+
+	acts, err := api.Report.QueryActivity(ctx, report.ActivityFilter{}, report.IntervalDay)
+	if err != nil {
+		t.Fatalf("Should be able to retrieve the product activity : %s", err)
+	}
+
+	if len(acts) != 1 || acts[0].TotalCount != len(prds) {
+		t.Logf("got: %v", acts)
+		t.Logf("exp: %v", len(prds))
+		t.Fatalf("Should bucket the products created today together.")
+	}
+
+	if acts[0].TotalValue != totalValue {
+		t.Logf("got: %v", acts[0].TotalValue)
+		t.Logf("exp: %v", totalValue)
+		t.Errorf("Should sum the value of the products in the bucket.")
+	}
+
+	// -------------------------------------------------------------------------
+
+	invs, err := api.Report.QueryInventory(ctx, report.InventoryFilter{}, report.DefaultInventoryOrderBy, 1, 10)
+	if err != nil {
+		t.Fatalf("Should be able to retrieve the inventory : %s", err)
+	}
+
+	if len(invs) != 0 {
+		t.Logf("got: %v", invs)
+		t.Fatalf("Should not see the products before the inventory is refreshed.")
+	}
+
+	if err := api.Report.RefreshInventory(ctx); err != nil {
+		t.Fatalf("Should be able to refresh the inventory : %s", err)
+	}
+
+	invs, err = api.Report.QueryInventory(ctx, report.InventoryFilter{}, report.DefaultInventoryOrderBy, 1, 10)
+	if err != nil {
+		t.Fatalf("Should be able to retrieve the inventory : %s", err)
+	}
+
+	if len(invs) != 1 || invs[0].UserID != usrs[0].ID || invs[0].TotalCount != len(prds) {
+		t.Logf("got: %v", invs)
+		t.Logf("exp: %v", usrs[0].ID)
+		t.Fatalf("Should only report the users with products.")
+	}
+
+	if invs[0].TotalValue != totalValue {
+		t.Logf("got: %v", invs[0].TotalValue)
+		t.Logf("exp: %v", totalValue)
+		t.Errorf("Should sum the value of the products of the user.")
+	}
+
+	// -------------------------------------------------------------------------
+
+	var filter report.InventoryFilter
+	filter.WithIncludeEmpty(true)
+
+	n, err := api.User.Count(ctx, user.QueryFilter{})
+	if err != nil {
+		t.Fatalf("Should be able to count the users : %s", err)
+	}
+
+	total, err := api.Report.CountInventory(ctx, filter)
+	if err != nil {
+		t.Fatalf("Should be able to count the inventory : %s", err)
+	}
+
+	if total != n {
+		t.Logf("got: %v", total)
+		t.Logf("exp: %v", n)
+		t.Errorf("Should report every user when empty users are included.")
+	}
+
+	invs, err = api.Report.QueryInventory(ctx, filter, order.NewBy(report.OrderByTotalValue, order.ASC), 1, 1)
+	if err != nil {
+		t.Fatalf("Should be able to retrieve the inventory : %s", err)
+	}
+
+	if len(invs) != 1 || invs[0].TotalCount != 0 {
+		t.Logf("got: %v", invs)
+		t.Errorf("Should page the inventory starting with the lowest value.")
+	}
+}
+
+func refreshLock(t *testing.T) {
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	api := test.CoreAPIs
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// -------------------------------------------------------------------------
+
+	f := func(tranCtx context.Context) error {
+		const q = `SELECT pg_advisory_xact_lock(hashtext('user_inventory'))`
+		if err := database.ExecContext(tranCtx, test.Log, test.DB, q); err != nil {
+			return err
+		}
+
+		if err := api.Report.RefreshInventory(ctx); !errors.Is(err, report.ErrRefreshing) {
+			t.Errorf("Should NOT be able to refresh the inventory while it's locked : %v", err)
+		}
+
+		return nil
+	}
+
+	if err := database.WithinTran(ctx, test.Log, test.DB, f); err != nil {
+		t.Fatalf("Should be able to lock the inventory refresh : %s", err)
+	}
+
+	if err := api.Report.RefreshInventory(ctx); err != nil {
+		t.Errorf("Should be able to refresh the inventory once unlocked : %s", err)
+	}
+}
@@ -0,0 +1,55 @@
+package reportdb
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/ardanlabs/service/business/cview/report"
+)
+
+func applyActivityFilter(filter report.ActivityFilter, data map[string]interface{}, buf *bytes.Buffer) {
+	var wc []string
+
+	if filter.UserID != nil {
+		data["user_id"] = *filter.UserID
+		wc = append(wc, "user_id = :user_id")
+	}
+
+	if filter.StartDate != nil {
+		data["start_date"] = *filter.StartDate
+		wc = append(wc, "date_created >= :start_date")
+	}
+
+	if filter.EndDate != nil {
+		data["end_date"] = *filter.EndDate
+		wc = append(wc, "date_created <= :end_date")
+	}
+
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+}
+
+func applyInventoryFilter(filter report.InventoryFilter, data map[string]interface{}, buf *bytes.Buffer) {
+	var wc []string
+
+	if filter.UserID != nil {
+		data["user_id"] = *filter.UserID
+		wc = append(wc, "user_id = :user_id")
+	}
+
+	if filter.Department != nil {
+		data["department"] = *filter.Department
+		wc = append(wc, "department = :department")
+	}
+
+	if filter.IncludeEmpty == nil || !*filter.IncludeEmpty {
+		wc = append(wc, "total_count > 0")
+	}
+
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+}
@@ -0,0 +1,64 @@
+package reportdb
+
+import (
+	"time"
+
+	"github.com/ardanlabs/service/business/cview/report"
+	"github.com/google/uuid"
+)
+
+type dbActivity struct {
+	Start      time.Time `db:"start"`
+	TotalCount int       `db:"total_count"`
+	TotalValue float64   `db:"total_value"`
+}
+
+func toCoreActivity(dbAct dbActivity) report.Activity {
+	act := report.Activity{
+		Start:      dbAct.Start.In(time.Local),
+		TotalCount: dbAct.TotalCount,
+		TotalValue: dbAct.TotalValue,
+	}
+
+	return act
+}
+
+func toCoreActivitySlice(dbActs []dbActivity) []report.Activity {
+	acts := make([]report.Activity, len(dbActs))
+	for i, dbAct := range dbActs {
+		acts[i] = toCoreActivity(dbAct)
+	}
+	return acts
+}
+
+// =============================================================================
+
+type dbInventory struct {
+	UserID        uuid.UUID `db:"user_id"`
+	UserName      string    `db:"user_name"`
+	Department    string    `db:"department"`
+	TotalCount    int       `db:"total_count"`
+	TotalQuantity int       `db:"total_quantity"`
+	TotalValue    float64   `db:"total_value"`
+}
+
+func toCoreInventory(dbInv dbInventory) report.Inventory {
+	inv := report.Inventory{
+		UserID:        dbInv.UserID,
+		UserName:      dbInv.UserName,
+		Department:    dbInv.Department,
+		TotalCount:    dbInv.TotalCount,
+		TotalQuantity: dbInv.TotalQuantity,
+		TotalValue:    dbInv.TotalValue,
+	}
+
+	return inv
+}
+
+func toCoreInventorySlice(dbInvs []dbInventory) []report.Inventory {
+	invs := make([]report.Inventory, len(dbInvs))
+	for i, dbInv := range dbInvs {
+		invs[i] = toCoreInventory(dbInv)
+	}
+	return invs
+}
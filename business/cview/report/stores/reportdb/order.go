@@ -0,0 +1,24 @@
+package reportdb
+
+import (
+	"github.com/ardanlabs/service/business/cview/report"
+	"github.com/ardanlabs/service/business/data/order"
+)
+
+var orderByInventoryFields = map[string]string{
+	report.OrderByUserID:        "user_id",
+	report.OrderByUserName:      "user_name",
+	report.OrderByDepartment:    "department",
+	report.OrderByTotalCount:    "total_count",
+	report.OrderByTotalQuantity: "total_quantity",
+	report.OrderByTotalValue:    "total_value",
+}
+
+func orderByInventoryClause(orderBy order.By) (string, error) {
+	clause, err := order.Clause(orderBy, orderByInventoryFields, "user_id")
+	if err != nil {
+		return "", err
+	}
+
+	return " ORDER BY " + clause, nil
+}
@@ -0,0 +1,141 @@
+// Package reportdb contains the report related queries.
+package reportdb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/ardanlabs/service/business/cview/report"
+	"github.com/ardanlabs/service/business/data/order"
+	"github.com/ardanlabs/service/business/sys/database"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for report database access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  *database.DB
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *zap.SugaredLogger, db *database.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// QueryActivity buckets the products by the date they were created.
+func (s *Store) QueryActivity(ctx context.Context, filter report.ActivityFilter, interval report.Interval) ([]report.Activity, error) {
+	data := map[string]interface{}{
+		"interval": interval.Name(),
+	}
+
+	const q = `
+	SELECT
+		date_trunc(:interval, date_created) AS start,
+		count(1)                            AS total_count,
+		COALESCE(SUM(cost * quantity), 0)   AS total_value
+	FROM
+		products`
+
+	buf := bytes.NewBufferString(q)
+	applyActivityFilter(filter, data, buf)
+	buf.WriteString(" GROUP BY 1 ORDER BY 1")
+
+	var dbActs []dbActivity
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbActs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreActivitySlice(dbActs), nil
+}
+
+// QueryInventory reads a page of the inventory from the user_inventory
+// materialized view, which holds a row for every user.
+func (s *Store) QueryInventory(ctx context.Context, filter report.InventoryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]report.Inventory, error) {
+	data := map[string]interface{}{
+		"offset":        (pageNumber - 1) * rowsPerPage,
+		"rows_per_page": rowsPerPage,
+	}
+
+	const q = `
+	SELECT
+		user_id, user_name, department, total_count, total_quantity, total_value
+	FROM
+		user_inventory`
+
+	buf := bytes.NewBufferString(q)
+	applyInventoryFilter(filter, data, buf)
+
+	orderByClause, err := orderByInventoryClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(orderByClause)
+	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+
+	var dbInvs []dbInventory
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbInvs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreInventorySlice(dbInvs), nil
+}
+
+// CountInventory returns the total number of users in the inventory.
+func (s *Store) CountInventory(ctx context.Context, filter report.InventoryFilter) (int, error) {
+	data := map[string]interface{}{}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		user_inventory`
+
+	buf := bytes.NewBufferString(q)
+	applyInventoryFilter(filter, data, buf)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// RefreshInventory recomputes the user_inventory materialized view. The view
+// is refreshed concurrently so it can still be read while it's recomputed.
+// The refresh holds a transaction level advisory lock, so an instance finding
+// the lock taken returns report.ErrRefreshing instead of queueing behind the
+// refresh already running.
+func (s *Store) RefreshInventory(ctx context.Context) error {
+	f := func(ctx context.Context) error {
+		const lq = `SELECT pg_try_advisory_xact_lock(hashtext('user_inventory')) AS locked`
+
+		var lock struct {
+			Locked bool `db:"locked"`
+		}
+		if err := database.QueryStruct(ctx, s.log, s.db, lq, &lock); err != nil {
+			return fmt.Errorf("querystruct: %w", err)
+		}
+
+		if !lock.Locked {
+			return report.ErrRefreshing
+		}
+
+		const q = `REFRESH MATERIALIZED VIEW CONCURRENTLY user_inventory`
+
+		if err := database.ExecContext(ctx, s.log, s.db, q); err != nil {
+			return fmt.Errorf("execcontext: %w", err)
+		}
+
+		return nil
+	}
+
+	return database.WithinTran(ctx, s.log, s.db, f)
+}
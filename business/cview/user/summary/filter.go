@@ -11,6 +11,10 @@ import (
 type QueryFilter struct {
 	UserID   *uuid.UUID `validate:"omitempty,uuid4"`
 	UserName *string    `validate:"omitempty,min=3"`
+
+	// IncludeEmpty includes the users who have no products, which are
+	// left out by default.
+	IncludeEmpty *bool
 }
 
 // Validate checks the data in the model is considered clean.
@@ -30,3 +34,8 @@ func (qf *QueryFilter) WithUserID(userID uuid.UUID) {
 func (qf *QueryFilter) WithUserName(userName string) {
 	qf.UserName = &userName
 }
+
+// WithIncludeEmpty sets the IncludeEmpty field of the QueryFilter value.
+func (qf *QueryFilter) WithIncludeEmpty(includeEmpty bool) {
+	qf.IncludeEmpty = &includeEmpty
+}
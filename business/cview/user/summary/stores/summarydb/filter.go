@@ -8,6 +8,16 @@ import (
 	"github.com/ardanlabs/service/business/cview/user/summary"
 )
 
+// view returns the view to query. The user_summary view joins the users with
+// their products, so users without products are only found in user_summary_all.
+func view(filter summary.QueryFilter) string {
+	if filter.IncludeEmpty != nil && *filter.IncludeEmpty {
+		return "user_summary_all"
+	}
+
+	return "user_summary"
+}
+
 func (s *Store) applyFilter(filter summary.QueryFilter, data map[string]interface{}, buf *bytes.Buffer) {
 	var wc []string
 
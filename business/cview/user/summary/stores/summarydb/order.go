@@ -1,8 +1,6 @@
 package summarydb
 
 import (
-	"fmt"
-
 	"github.com/ardanlabs/service/business/cview/user/summary"
 	"github.com/ardanlabs/service/business/data/order"
 )
@@ -13,10 +11,10 @@ var orderByFields = map[string]string{
 }
 
 func orderByClause(orderBy order.By) (string, error) {
-	by, exists := orderByFields[orderBy.Field]
-	if !exists {
-		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	clause, err := order.Clause(orderBy, orderByFields, "user_id")
+	if err != nil {
+		return "", err
 	}
 
-	return " ORDER BY " + by + " " + orderBy.Direction, nil
+	return " ORDER BY " + clause, nil
 }
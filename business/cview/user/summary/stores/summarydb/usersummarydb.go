@@ -8,19 +8,18 @@ import (
 
 	"github.com/ardanlabs/service/business/cview/user/summary"
 	"github.com/ardanlabs/service/business/data/order"
-	database "github.com/ardanlabs/service/business/sys/database/pq"
-	"github.com/jmoiron/sqlx"
+	"github.com/ardanlabs/service/business/sys/database"
 	"go.uber.org/zap"
 )
 
 // Store manages the set of APIs for user database access.
 type Store struct {
 	log *zap.SugaredLogger
-	db  sqlx.ExtContext
+	db  *database.DB
 }
 
 // NewStore constructs the api for data access.
-func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+func NewStore(log *zap.SugaredLogger, db *database.DB) *Store {
 	return &Store{
 		log: log,
 		db:  db,
@@ -36,11 +35,11 @@ func (s *Store) Query(ctx context.Context, filter summary.QueryFilter, orderBy o
 
 	const q = `
 	SELECT
-		*
+		user_id, user_name, total_count, total_cost
 	FROM
-		user_summary`
+		`
 
-	buf := bytes.NewBufferString(q)
+	buf := bytes.NewBufferString(q + view(filter))
 	s.applyFilter(filter, data, buf)
 
 	orderByClause, err := orderByClause(orderBy)
@@ -67,9 +66,9 @@ func (s *Store) Count(ctx context.Context, filter summary.QueryFilter) (int, err
 	SELECT
 		count(1)
 	FROM
-		user_summary`
+		`
 
-	buf := bytes.NewBufferString(q)
+	buf := bytes.NewBufferString(q + view(filter))
 	s.applyFilter(filter, data, buf)
 
 	var count struct {
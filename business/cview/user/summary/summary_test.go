@@ -37,7 +37,7 @@ func Test_Product(t *testing.T) {
 
 func paging(t *testing.T) {
 	seed := func(ctx context.Context, usrCore *user.Core, prdCore *product.Core) ([]user.User, []product.Product, error) {
-		usrs, err := usrCore.Query(ctx, user.QueryFilter{}, order.By{Field: user.OrderByName, Direction: order.ASC}, 1, 2)
+		usrs, err := usrCore.Query(ctx, user.QueryFilter{}, order.NewBy(user.OrderByName, order.ASC), 1, 2)
 		if err != nil {
 			return nil, nil, fmt.Errorf("seeding users : %w", err)
 		}
@@ -84,7 +84,7 @@ func paging(t *testing.T) {
 
 	// -------------------------------------------------------------------------
 
-	prd1, err := api.UserViews.Summary.Query(ctx, summary.QueryFilter{}, order.By{Field: summary.OrderByUserName, Direction: order.ASC}, 1, 10)
+	prd1, err := api.UserViews.Summary.Query(ctx, summary.QueryFilter{}, order.NewBy(summary.OrderByUserName, order.ASC), 1, 10)
 	if err != nil {
 		t.Fatalf("Should be able to retrieve user summary : %s", err)
 	}
@@ -157,4 +157,24 @@ func paging(t *testing.T) {
 			}
 		})
 	}
+	// -------------------------------------------------------------------------
+
+	var filter summary.QueryFilter
+	filter.WithIncludeEmpty(true)
+
+	n, err = api.UserViews.Summary.Count(ctx, filter)
+	if err != nil {
+		t.Fatalf("Should be able to retrieve user summary count : %s", err)
+	}
+
+	nUsrs, err := api.User.Count(ctx, user.QueryFilter{})
+	if err != nil {
+		t.Fatalf("Should be able to count the users : %s", err)
+	}
+
+	if n != nUsrs {
+		t.Log("got:", n)
+		t.Log("exp:", nUsrs)
+		t.Error("Should include the users without products")
+	}
 }
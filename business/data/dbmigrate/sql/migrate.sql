@@ -43,3 +43,84 @@ JOIN
     products AS p ON p.user_id = u.user_id
 GROUP BY
     u.user_id
+
+-- Version: 1.04
+-- Description: Create table rate_limits
+CREATE TABLE rate_limits (
+	limit_key    TEXT             NOT NULL,
+	tokens       DOUBLE PRECISION NOT NULL,
+	date_updated TIMESTAMP        NOT NULL,
+
+	PRIMARY KEY (limit_key)
+);
+
+-- Version: 1.05
+-- Description: Create table idempotency_keys
+CREATE TABLE idempotency_keys (
+	subject         TEXT      NOT NULL,
+	idempotency_key TEXT      NOT NULL,
+	fingerprint     TEXT      NOT NULL,
+	status          INT       NOT NULL,
+	body            BYTEA     NULL,
+	date_created    TIMESTAMP NOT NULL,
+	date_expires    TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (subject, idempotency_key)
+);
+
+-- Version: 1.06
+-- Description: Add full-text and trigram search to products and users
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE products ADD COLUMN search TSVECTOR
+	GENERATED ALWAYS AS (to_tsvector('english', name)) STORED;
+CREATE INDEX products_search_idx ON products USING GIN (search);
+CREATE INDEX products_name_trgm_idx ON products USING GIN (name gin_trgm_ops);
+
+ALTER TABLE users ADD COLUMN search TSVECTOR
+	GENERATED ALWAYS AS (to_tsvector('english', name || ' ' || coalesce(department, ''))) STORED;
+CREATE INDEX users_search_idx ON users USING GIN (search);
+CREATE INDEX users_name_trgm_idx ON users USING GIN (name gin_trgm_ops);
+
+-- Version: 1.07
+-- Description: Add reporting views that include users without products
+CREATE OR REPLACE VIEW user_summary_all AS
+SELECT
+	u.user_id                AS user_id,
+	u.name                   AS user_name,
+	COUNT(p.product_id)      AS total_count,
+	COALESCE(SUM(p.cost), 0) AS total_cost
+FROM
+	users AS u
+LEFT JOIN
+	products AS p ON p.user_id = u.user_id
+GROUP BY
+	u.user_id;
+
+CREATE MATERIALIZED VIEW user_inventory AS
+SELECT
+	u.user_id                             AS user_id,
+	u.name                                AS user_name,
+	COALESCE(u.department, '')            AS department,
+	COUNT(p.product_id)                   AS total_count,
+	COALESCE(SUM(p.quantity), 0)          AS total_quantity,
+	COALESCE(SUM(p.cost * p.quantity), 0) AS total_value
+FROM
+	users AS u
+LEFT JOIN
+	products AS p ON p.user_id = u.user_id
+GROUP BY
+	u.user_id;
+
+-- The unique index lets the view be refreshed without blocking readers.
+CREATE UNIQUE INDEX user_inventory_user_id_idx ON user_inventory (user_id);
+CREATE INDEX products_date_created_idx ON products (date_created);
+
+-- Version: 1.08
+-- Description: Track when rate limit buckets are full so they can be pruned
+ALTER TABLE rate_limits ADD COLUMN date_full TIMESTAMP NOT NULL DEFAULT NOW();
+CREATE INDEX rate_limits_date_full_idx ON rate_limits (date_full);
+
+-- Version: 1.09
+-- Description: Index idempotency keys by expiry so expired keys can be pruned
+CREATE INDEX idempotency_keys_date_expires_idx ON idempotency_keys (date_expires);
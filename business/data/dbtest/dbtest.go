@@ -17,14 +17,15 @@ import (
 	"github.com/ardanlabs/service/business/core/product/stores/productdb"
 	"github.com/ardanlabs/service/business/core/user"
 	"github.com/ardanlabs/service/business/core/user/stores/userdb"
+	"github.com/ardanlabs/service/business/cview/report"
+	"github.com/ardanlabs/service/business/cview/report/stores/reportdb"
 	"github.com/ardanlabs/service/business/cview/user/summary"
 	"github.com/ardanlabs/service/business/cview/user/summary/stores/summarydb"
 	"github.com/ardanlabs/service/business/data/dbmigrate"
-	database "github.com/ardanlabs/service/business/sys/database/pgx"
+	"github.com/ardanlabs/service/business/sys/database"
 	"github.com/ardanlabs/service/business/web/auth"
 	"github.com/ardanlabs/service/foundation/docker"
 	"github.com/golang-jwt/jwt/v4"
-	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -57,7 +58,7 @@ func StopDB(c *docker.Container) {
 
 // Test owns state for running and shutting down tests.
 type Test struct {
-	DB       *sqlx.DB
+	DB       *database.DB
 	Log      *zap.SugaredLogger
 	Auth     *auth.Auth
 	CoreAPIs CoreAPIs
@@ -85,7 +86,7 @@ func NewTest(t *testing.T, c *docker.Container) *Test {
 
 	t.Log("Waiting for database to be ready ...")
 
-	if err := database.StatusCheck(ctx, dbM); err != nil {
+	if err := database.StatusCheck(ctx, dbM.DB); err != nil {
 		t.Fatalf("status check database: %v", err)
 	}
 
@@ -118,12 +119,12 @@ func NewTest(t *testing.T, c *docker.Container) *Test {
 
 	t.Log("Migrate and seed database ...")
 
-	if err := dbmigrate.Migrate(ctx, db); err != nil {
+	if err := dbmigrate.Migrate(ctx, db.DB); err != nil {
 		t.Logf("Logs for %s\n%s:", c.ID, docker.DumpContainerLogs(c.ID))
 		t.Fatalf("Migrating error: %s", err)
 	}
 
-	if err := dbmigrate.Seed(ctx, db); err != nil {
+	if err := dbmigrate.Seed(ctx, db.DB); err != nil {
 		t.Logf("Logs for %s\n%s:", c.ID, docker.DumpContainerLogs(c.ID))
 		t.Fatalf("Seeding error: %s", err)
 	}
@@ -246,10 +247,11 @@ type UserViews struct {
 type CoreAPIs struct {
 	User      *user.Core
 	Product   *product.Core
+	Report    *report.Core
 	UserViews UserViews
 }
 
-func newCoreAPIs(log *zap.SugaredLogger, db *sqlx.DB) CoreAPIs {
+func newCoreAPIs(log *zap.SugaredLogger, db *database.DB) CoreAPIs {
 	evnCore := event.NewCore(log)
 	usrCore := user.NewCore(evnCore, userdb.NewStore(log, db))
 	prdCore := product.NewCore(log, evnCore, usrCore, productdb.NewStore(log, db))
@@ -257,6 +259,7 @@ func newCoreAPIs(log *zap.SugaredLogger, db *sqlx.DB) CoreAPIs {
 	return CoreAPIs{
 		User:    usrCore,
 		Product: prdCore,
+		Report:  report.NewCore(log, reportdb.NewStore(log, db)),
 		UserViews: UserViews{
 			Summary: summary.NewCore(summarydb.NewStore(log, db)),
 		},
@@ -2,9 +2,9 @@
 package order
 
 import (
-	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 
 	"github.com/ardanlabs/service/business/sys/validate"
@@ -17,52 +17,160 @@ const (
 )
 
 var directions = map[string]string{
-	ASC:  "ASC",
-	DESC: "DESC",
+	"asc":  ASC,
+	"desc": DESC,
+}
+
+// Set of positions for the null values of a field.
+const (
+	NullsFirst = "NULLS FIRST"
+	NullsLast  = "NULLS LAST"
+)
+
+var nulls = map[string]string{
+	"nullsfirst": NullsFirst,
+	"nullslast":  NullsLast,
 }
 
 // =============================================================================
 
-// By represents a field used to order by and direction.
-type By struct {
+// Key represents a field used to order by, its direction and where the null
+// values of the field go. The database default is used when Nulls is empty.
+type Key struct {
 	Field     string
 	Direction string
+	Nulls     string
 }
 
-// NewBy constructs a new By value with no checks.
+// By represents the keys used to order by, in order of precedence.
+type By []Key
+
+// NewBy constructs a new By value for a single field with no checks.
 func NewBy(field string, direction string) By {
 	return By{
-		Field:     field,
-		Direction: direction,
+		{
+			Field:     field,
+			Direction: direction,
+		},
 	}
 }
 
 // =============================================================================
 
-// Parse constructs a order.By value by parsing a string in the form
-// of "field,direction".
-func Parse(r *http.Request, defaultOrder By) (By, error) {
+// Parse constructs a order.By value by parsing the orderBy query parameter,
+// a comma separated list of keys in the form of "field[:direction[:nulls]]"
+// like "cost:desc:nullslast,name". The direction is asc or desc, asc by
+// default, and nulls is nullsfirst or nullslast. The legacy form of
+// "field,direction" is accepted as well. Every field must be one of the
+// specified fields.
+func Parse(r *http.Request, defaultOrder By, fields map[string]struct{}) (By, error) {
 	v := r.URL.Query().Get("orderBy")
 
 	if v == "" {
 		return defaultOrder, nil
 	}
 
-	orderParts := strings.Split(v, ",")
+	parts := strings.Split(v, ",")
 
-	var by By
-	switch len(orderParts) {
-	case 1:
-		by = NewBy(strings.Trim(orderParts[0], " "), ASC)
-	case 2:
-		by = NewBy(strings.Trim(orderParts[0], " "), strings.Trim(orderParts[1], " "))
-	default:
-		return By{}, validate.NewFieldsError(v, errors.New("unknown order field"))
+	if len(parts) == 2 {
+		if direction, exists := directions[strings.ToLower(strings.TrimSpace(parts[1]))]; exists {
+			parts = []string{strings.TrimSpace(parts[0]) + ":" + direction}
+		}
 	}
 
-	if _, exists := directions[by.Direction]; !exists {
-		return By{}, validate.NewFieldsError(v, fmt.Errorf("unknown direction: %s", by.Direction))
+	by := make(By, 0, len(parts))
+	for _, part := range parts {
+		key, err := parseKey(strings.TrimSpace(part))
+		if err != nil {
+			return By{}, validate.NewFieldsError("orderBy", err)
+		}
+
+		if _, exists := fields[key.Field]; !exists {
+			return By{}, validate.NewFieldsError("orderBy", fmt.Errorf("unknown field %q, valid fields are: %s", key.Field, validFields(fields)))
+		}
+
+		by = append(by, key)
 	}
 
 	return by, nil
 }
+
+func parseKey(v string) (Key, error) {
+	parts := strings.Split(v, ":")
+	if len(parts) > 3 || parts[0] == "" {
+		return Key{}, fmt.Errorf("invalid order key %q", v)
+	}
+
+	key := Key{
+		Field:     parts[0],
+		Direction: ASC,
+	}
+
+	if len(parts) > 1 {
+		direction, exists := directions[strings.ToLower(parts[1])]
+		if !exists {
+			return Key{}, fmt.Errorf("unknown direction %q, valid directions are: asc, desc", parts[1])
+		}
+		key.Direction = direction
+	}
+
+	if len(parts) > 2 {
+		pos, exists := nulls[strings.ToLower(parts[2])]
+		if !exists {
+			return Key{}, fmt.Errorf("unknown nulls position %q, valid positions are: nullsfirst, nullslast", parts[2])
+		}
+		key.Nulls = pos
+	}
+
+	return key, nil
+}
+
+func validFields(fields map[string]struct{}) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
+
+// =============================================================================
+
+// Clause returns the expressions of an ORDER BY clause for the keys, using the
+// columns the fields map to. The tiebreaker column, normally the primary key,
+// is appended when it isn't already used, so rows with equal keys are always
+// returned in the same order and paging is stable.
+func Clause(by By, columns map[string]string, tiebreaker string) (string, error) {
+	exprs := make([]string, 0, len(by)+1)
+	used := false
+
+	for _, key := range by {
+		column, exists := columns[key.Field]
+		if !exists {
+			return "", fmt.Errorf("field %q does not exist", key.Field)
+		}
+
+		if _, exists := directions[strings.ToLower(key.Direction)]; !exists {
+			return "", fmt.Errorf("direction %q does not exist", key.Direction)
+		}
+
+		expr := column + " " + strings.ToUpper(key.Direction)
+		switch key.Nulls {
+		case "":
+		case NullsFirst, NullsLast:
+			expr += " " + key.Nulls
+		default:
+			return "", fmt.Errorf("nulls position %q does not exist", key.Nulls)
+		}
+
+		exprs = append(exprs, expr)
+		used = used || column == tiebreaker
+	}
+
+	if !used {
+		exprs = append(exprs, tiebreaker+" "+ASC)
+	}
+
+	return strings.Join(exprs, ", "), nil
+}
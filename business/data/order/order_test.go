@@ -0,0 +1,104 @@
+package order_test
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/business/core/product"
+	"github.com/ardanlabs/service/business/data/order"
+	"github.com/ardanlabs/service/business/sys/validate"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Test_OrderParse validates the orderBy query parameter grammar.
+func Test_OrderParse(t *testing.T) {
+	t.Parallel()
+
+	fields := map[string]struct{}{
+		product.OrderByProdID: {},
+		product.OrderByName:   {},
+		product.OrderByCost:   {},
+	}
+
+	tt := []struct {
+		orderBy string
+		exp     order.By
+	}{
+		{"", product.DefaultOrderBy},
+		{"name", order.By{{Field: "name", Direction: order.ASC}}},
+		{"name,DESC", order.By{{Field: "name", Direction: order.DESC}}},
+		{"cost:desc,name:asc", order.By{{Field: "cost", Direction: order.DESC}, {Field: "name", Direction: order.ASC}}},
+		{"cost:desc:nullslast, name", order.By{{Field: "cost", Direction: order.DESC, Nulls: order.NullsLast}, {Field: "name", Direction: order.ASC}}},
+	}
+
+	for _, tst := range tt {
+		r := httptest.NewRequest("GET", "/v1/products?orderBy="+url.QueryEscape(tst.orderBy), nil)
+
+		got, err := order.Parse(r, product.DefaultOrderBy, fields)
+		if err != nil {
+			t.Fatalf("Should be able to parse %q: %s", tst.orderBy, err)
+		}
+
+		if diff := cmp.Diff(got, tst.exp); diff != "" {
+			t.Fatalf("Should get back the keys of %q, diff:\n%s", tst.orderBy, diff)
+		}
+	}
+
+	for _, orderBy := range []string{"sold:desc", "name:up", "name:asc:nullsmiddle", "name:asc:nullsfirst:x"} {
+		r := httptest.NewRequest("GET", "/v1/products?orderBy="+url.QueryEscape(orderBy), nil)
+
+		_, err := order.Parse(r, product.DefaultOrderBy, fields)
+
+		fe := validate.GetFieldErrors(err)
+		if len(fe) != 1 || fe[0].Field != "orderBy" {
+			t.Logf("got: %v", err)
+			t.Fatalf("Should get a field error for %q", orderBy)
+		}
+	}
+
+	r := httptest.NewRequest("GET", "/v1/products?orderBy=sold", nil)
+	_, err := order.Parse(r, product.DefaultOrderBy, fields)
+	if !strings.Contains(err.Error(), "cost, name, productid") {
+		t.Logf("got: %v", err)
+		t.Fatalf("Should list the valid fields.")
+	}
+}
+
+// Test_OrderClause validates the ORDER BY expressions built for the keys.
+func Test_OrderClause(t *testing.T) {
+	t.Parallel()
+
+	columns := map[string]string{
+		product.OrderByProdID: "product_id",
+		product.OrderByName:   "name",
+		product.OrderByCost:   "cost",
+	}
+
+	tt := []struct {
+		by  order.By
+		exp string
+	}{
+		{order.NewBy(product.OrderByName, order.DESC), "name DESC, product_id ASC"},
+		{order.NewBy(product.OrderByProdID, order.DESC), "product_id DESC"},
+		{order.By{{Field: "cost", Direction: order.DESC, Nulls: order.NullsFirst}, {Field: "name", Direction: order.ASC}}, "cost DESC NULLS FIRST, name ASC, product_id ASC"},
+	}
+
+	for _, tst := range tt {
+		got, err := order.Clause(tst.by, columns, "product_id")
+		if err != nil {
+			t.Fatalf("Should be able to build the clause: %s", err)
+		}
+
+		if got != tst.exp {
+			t.Logf("got: %v", got)
+			t.Logf("exp: %v", tst.exp)
+			t.Fatalf("Should get back the expected clause.")
+		}
+	}
+
+	if _, err := order.Clause(order.NewBy(product.OrderByName, "; DROP TABLE products"), columns, "product_id"); err == nil {
+		t.Fatalf("Should NOT accept an unknown direction.")
+	}
+}
@@ -0,0 +1,433 @@
+// Package database provides support for access the database.
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/ardanlabs/service/business/sys/database/querylog"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/prom"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// Set of error variables for CRUD operations. The driver errors are mapped to
+// these variables so the stores don't depend on a driver.
+var (
+	ErrDBNotFound           = sql.ErrNoRows
+	ErrDBDuplicatedEntry    = errors.New("duplicated entry")
+	ErrUndefinedTable       = errors.New("undefined table")
+	ErrForeignKeyViolation  = errors.New("foreign key violation")
+	ErrSerializationFailure = errors.New("serialization failure")
+	ErrDeadlock             = errors.New("deadlock detected")
+	ErrConnectionFailure    = errors.New("connection failure")
+)
+
+// queryDuration tracks the duration of the queries executed by the helper
+// functions, labeled by the kind of operation.
+var queryDuration = prom.NewHistogramVec(
+	"db_query_duration_seconds",
+	"Duration of database queries by operation.",
+	prom.DefBuckets,
+	"operation",
+)
+
+// Config is the required properties to use the database.
+type Config struct {
+	Driver       string
+	User         string
+	Password     string
+	Host         string
+	Name         string
+	Schema       string
+	MaxIdleConns int
+	MaxOpenConns int
+	DisableTLS   bool
+	Replicas     []string
+}
+
+// Open knows how to open a database connection based on the configuration.
+// The pgx driver is used when no driver is specified. A connection is opened
+// for every replica host, using the same credentials as the primary.
+func Open(cfg Config) (*DB, error) {
+	driverName := cfg.Driver
+	if driverName == "" {
+		driverName = DriverPgx
+	}
+	if _, exists := drivers[driverName]; !exists {
+		return nil, fmt.Errorf("unknown driver %q", driverName)
+	}
+
+	primary, err := open(driverName, cfg, cfg.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	db := DB{
+		DB:       primary,
+		shutdown: make(chan struct{}),
+	}
+
+	for _, host := range cfg.Replicas {
+		rdb, err := open(driverName, cfg, host)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("replica %s: %w", host, err)
+		}
+
+		db.replicas = append(db.replicas, &replica{
+			db:      rdb,
+			host:    host,
+			healthy: 1,
+		})
+	}
+
+	return &db, nil
+}
+
+func open(driverName string, cfg Config, host string) (*sqlx.DB, error) {
+	sslMode := "require"
+	if cfg.DisableTLS {
+		sslMode = "disable"
+	}
+
+	q := make(url.Values)
+	q.Set("sslmode", sslMode)
+	q.Set("timezone", "utc")
+	if cfg.Schema != "" {
+		q.Set("search_path", cfg.Schema)
+	}
+
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.User, cfg.Password),
+		Host:     host,
+		Path:     cfg.Name,
+		RawQuery: q.Encode(),
+	}
+
+	db, err := sqlx.Open(driverName, u.String())
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+
+	return db, nil
+}
+
+// StatusCheck returns nil if it can successfully talk to the database. It
+// returns a non-nil error otherwise.
+func StatusCheck(ctx context.Context, db *sqlx.DB) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Second)
+		defer cancel()
+	}
+
+	var pingError error
+	for attempts := 1; ; attempts++ {
+		pingError = db.Ping()
+		if pingError == nil {
+			break
+		}
+		time.Sleep(time.Duration(attempts) * 100 * time.Millisecond)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	// Run a simple query to determine connectivity.
+	// Running this query forces a round trip through the database.
+	const q = `SELECT true`
+	var tmp bool
+	return db.QueryRowContext(ctx, q).Scan(&tmp)
+}
+
+// WithinTran runs the function inside a transaction and does the commit or
+// rollback at the end. The transaction is carried in the context passed to the
+// function, so every helper in this package called with that context and the
+// same db takes part in it. When ctx already carries a transaction for db, the
+// function runs inside a savepoint that is rolled back on error without
+// aborting the outer transaction, and the options are ignored. A transaction
+// can't be used concurrently.
+//
+// The transaction runs again with a jittered backoff when it fails with a
+// serialization failure, a deadlock or a connection failure, so the function
+// must be safe to run more than once.
+func WithinTran(ctx context.Context, log *zap.SugaredLogger, db *DB, fn func(ctx context.Context) error, options ...func(opts *TranOptions)) error {
+	log = logger.WithContext(ctx, log.Named("database"))
+
+	if t, ok := ctx.Value(tranKey).(*tran); ok && t.db == db {
+		return withinSavepoint(ctx, log, t, fn)
+	}
+
+	opts := TranOptions{
+		retries: DefaultRetries,
+	}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	ctx, span := web.AddSpan(ctx, "business.sys.database.tran",
+		attribute.String("isolation", opts.isolation.String()),
+		attribute.Bool("read_only", opts.readOnly),
+	)
+	defer span.End()
+
+	for attempt := 0; ; attempt++ {
+		retry, err := withinTran(ctx, log, db, opts, fn)
+		if err == nil || !retry || attempt == opts.retries {
+			span.SetAttributes(attribute.Int("retries", attempt))
+			return err
+		}
+
+		delay := backoff(attempt)
+		log.Infow("retry tran", "attempt", attempt+1, "delay", delay, "ERROR", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			span.SetAttributes(attribute.Int("retries", attempt))
+			return fmt.Errorf("retry tran: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// withinTran runs the function inside a single transaction. It reports if the
+// transaction can run again after the error.
+func withinTran(ctx context.Context, log *zap.SugaredLogger, db *DB, opts TranOptions, fn func(ctx context.Context) error) (bool, error) {
+	log.Infow("begin tran")
+	tx, err := db.BeginTxx(ctx, &sql.TxOptions{Isolation: opts.isolation, ReadOnly: opts.readOnly})
+	if err != nil {
+		err = toError(db, err)
+		return retryable(err), fmt.Errorf("begin tran: %w", err)
+	}
+
+	// We can defer the rollback since the code checks if the transaction
+	// has already been committed.
+	defer func() {
+		if err := tx.Rollback(); err != nil {
+			if errors.Is(err, sql.ErrTxDone) {
+				return
+			}
+			log.Errorw("unable to rollback tran", "ERROR", err)
+		}
+		log.Infow("rollback tran")
+	}()
+
+	ctx = context.WithValue(ctx, tranKey, &tran{db: db, tx: tx})
+
+	if err := fn(ctx); err != nil {
+		err = toError(db, err)
+		return retryable(err), fmt.Errorf("exec tran: %w", err)
+	}
+
+	// A connection failure during commit isn't retried since the commit may
+	// have been applied.
+	if err := tx.Commit(); err != nil {
+		err = toError(db, err)
+		return conflict(err), fmt.Errorf("commit tran: %w", err)
+	}
+	log.Infow("commit tran")
+
+	if !opts.readOnly {
+		markWrite(ctx)
+	}
+
+	return false, nil
+}
+
+// InTran reports whether the context carries a transaction.
+func InTran(ctx context.Context) bool {
+	_, ok := ctx.Value(tranKey).(*tran)
+	return ok
+}
+
+// ExecContext is a helper function to execute a CUD operation with
+// logging and tracing.
+func ExecContext(ctx context.Context, log *zap.SugaredLogger, db sqlx.ExtContext, query string) error {
+	return NamedExecContext(ctx, log, db, query, struct{}{})
+}
+
+// NamedExecContext is a helper function to execute a CUD operation with
+// logging and tracing where field replacement is necessary.
+func NamedExecContext(ctx context.Context, log *zap.SugaredLogger, db sqlx.ExtContext, query string, data any) error {
+	q := querylog.String(query, data)
+
+	if _, ok := data.(struct{}); ok {
+		logger.WithContext(ctx, log.Named("database").WithOptions(zap.AddCallerSkip(3))).Infow("database.NamedExecContext", "query", q)
+	} else {
+		logger.WithContext(ctx, log.Named("database").WithOptions(zap.AddCallerSkip(2))).Infow("database.NamedExecContext", "query", q)
+	}
+
+	ctx, span := web.AddSpan(ctx, "business.sys.database.exec", attribute.String("query", q))
+	defer span.End()
+	defer observeQuery("exec", time.Now())
+
+	db = conn(ctx, db)
+
+	if _, err := sqlx.NamedExecContext(ctx, db, query, data); err != nil {
+		return toError(db, err)
+	}
+	markWrite(ctx)
+
+	return nil
+}
+
+// QuerySlice is a helper function for executing queries that return a
+// collection of data to be unmarshalled into a slice.
+func QuerySlice[T any](ctx context.Context, log *zap.SugaredLogger, db sqlx.ExtContext, query string, dest *[]T) error {
+	return namedQuerySlice(ctx, log, db, query, struct{}{}, dest, false)
+}
+
+// NamedQuerySlice is a helper function for executing queries that return a
+// collection of data to be unmarshalled into a slice where field replacement is
+// necessary.
+func NamedQuerySlice[T any](ctx context.Context, log *zap.SugaredLogger, db sqlx.ExtContext, query string, data any, dest *[]T) error {
+	return namedQuerySlice(ctx, log, db, query, data, dest, false)
+}
+
+// NamedQuerySliceUsingIn is a helper function for executing queries that return
+// a collection of data to be unmarshalled into a slice where field replacement
+// is necessary. Use this if the query has an IN clause.
+func NamedQuerySliceUsingIn[T any](ctx context.Context, log *zap.SugaredLogger, db sqlx.ExtContext, query string, data any, dest *[]T) error {
+	return namedQuerySlice(ctx, log, db, query, data, dest, true)
+}
+
+func namedQuerySlice[T any](ctx context.Context, log *zap.SugaredLogger, db sqlx.ExtContext, query string, data any, dest *[]T, withIn bool) error {
+	q := querylog.String(query, data)
+
+	logger.WithContext(ctx, log.Named("database").WithOptions(zap.AddCallerSkip(3))).Infow("database.NamedQuerySlice", "query", q)
+
+	ctx, span := web.AddSpan(ctx, "business.sys.database.queryslice", attribute.String("query", q))
+	defer span.End()
+	defer observeQuery("queryslice", time.Now())
+
+	rows, err := queryRows(ctx, log, db, query, data, withIn)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var slice []T
+	for rows.Next() {
+		v := new(T)
+		if err := rows.StructScan(v); err != nil {
+			return err
+		}
+		slice = append(slice, *v)
+	}
+	*dest = slice
+
+	return nil
+}
+
+// QueryStruct is a helper function for executing queries that return a
+// single value to be unmarshalled into a struct type where field replacement is necessary.
+func QueryStruct(ctx context.Context, log *zap.SugaredLogger, db sqlx.ExtContext, query string, dest any) error {
+	return namedQueryStruct(ctx, log, db, query, struct{}{}, dest, false)
+}
+
+// NamedQueryStruct is a helper function for executing queries that return a
+// single value to be unmarshalled into a struct type where field replacement is necessary.
+func NamedQueryStruct(ctx context.Context, log *zap.SugaredLogger, db sqlx.ExtContext, query string, data any, dest any) error {
+	return namedQueryStruct(ctx, log, db, query, data, dest, false)
+}
+
+// NamedQueryStructUsingIn is a helper function for executing queries that return
+// a single value to be unmarshalled into a struct type where field replacement
+// is necessary. Use this if the query has an IN clause.
+func NamedQueryStructUsingIn(ctx context.Context, log *zap.SugaredLogger, db sqlx.ExtContext, query string, data any, dest any) error {
+	return namedQueryStruct(ctx, log, db, query, data, dest, true)
+}
+
+func namedQueryStruct(ctx context.Context, log *zap.SugaredLogger, db sqlx.ExtContext, query string, data any, dest any, withIn bool) error {
+	q := querylog.String(query, data)
+
+	logger.WithContext(ctx, log.Named("database").WithOptions(zap.AddCallerSkip(3))).Infow("database.NamedQueryStruct", "query", q)
+
+	ctx, span := web.AddSpan(ctx, "business.sys.database.query", attribute.String("query", q))
+	defer span.End()
+	defer observeQuery("query", time.Now())
+
+	rows, err := queryRows(ctx, log, db, query, data, withIn)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return ErrDBNotFound
+	}
+
+	if err := rows.StructScan(dest); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// queryRows runs the query against a replica when one can be used, otherwise
+// against the primary. A query that fails on a replica with a connection
+// error or a conflict runs again against the primary. Only a connection
+// error evicts the replica, since a hot standby cancels queries that
+// conflict with recovery as a serialization failure while staying healthy.
+func queryRows(ctx context.Context, log *zap.SugaredLogger, db sqlx.ExtContext, query string, data any, withIn bool) (*sqlx.Rows, error) {
+	rdb, r := reader(ctx, db)
+
+	rows, err := runQuery(ctx, rdb, query, data, withIn)
+	if err != nil && r != nil {
+		if dbErr := toError(rdb, err); retryable(dbErr) {
+			if connLost(dbErr) {
+				r.evict(log.Named("database"), err)
+			}
+			rdb = conn(ctx, db)
+			rows, err = runQuery(ctx, rdb, query, data, withIn)
+		}
+	}
+
+	if err != nil {
+		return nil, toError(rdb, err)
+	}
+
+	return rows, nil
+}
+
+func runQuery(ctx context.Context, db sqlx.ExtContext, query string, data any, withIn bool) (*sqlx.Rows, error) {
+	if !withIn {
+		return sqlx.NamedQueryContext(ctx, db, query, data)
+	}
+
+	named, args, err := sqlx.Named(query, data)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err = sqlx.In(named, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	query = db.Rebind(query)
+	return db.QueryxContext(ctx, query, args...)
+}
+
+// observeQuery records the duration of a query that started at the specified
+// time.
+func observeQuery(operation string, start time.Time) {
+	queryDuration.Observe(time.Since(start).Seconds(), operation)
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/lib/pq"
+)
+
+// Set of drivers that can be used to open a connection.
+const (
+	DriverPgx = "pgx"
+	DriverPq  = "postgres"
+)
+
+// Set of SQLSTATE codes that map to the error variables.
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+var codes = map[string]error{
+	"23505": ErrDBDuplicatedEntry,
+	"23503": ErrForeignKeyViolation,
+	"42P01": ErrUndefinedTable,
+	"40001": ErrSerializationFailure,
+	"40P01": ErrDeadlock,
+	"08000": ErrConnectionFailure,
+	"08003": ErrConnectionFailure,
+	"08006": ErrConnectionFailure,
+}
+
+// driver provides the behavior that differs between the drivers.
+type driver interface {
+
+	// Code returns the SQLSTATE code of an error returned by the driver.
+	Code(err error) (string, bool)
+}
+
+var drivers = map[string]driver{
+	DriverPgx: pgxDriver{},
+	DriverPq:  pqDriver{},
+}
+
+type pgxDriver struct{}
+
+func (pgxDriver) Code(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return "", false
+	}
+	return pgErr.Code, true
+}
+
+type pqDriver struct{}
+
+func (pqDriver) Code(err error) (string, bool) {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return "", false
+	}
+	return string(pqErr.Code), true
+}
+
+// =============================================================================
+
+// dbError is a driver error mapped to one of the error variables. It matches
+// the variable with errors.Is and keeps the driver error for errors.As.
+type dbError struct {
+	sentinel error
+	err      error
+}
+
+func (e *dbError) Error() string {
+	return e.sentinel.Error() + ": " + e.err.Error()
+}
+
+func (e *dbError) Is(target error) bool {
+	return target == e.sentinel
+}
+
+func (e *dbError) Unwrap() error {
+	return e.err
+}
+
+// toError maps the error returned by the driver behind db to one of the error
+// variables when its code is known, otherwise the error is returned as is.
+func toError(db any, err error) error {
+	var dbErr *dbError
+	if err == nil || errors.As(err, &dbErr) {
+		return err
+	}
+
+	dn, ok := db.(interface{ DriverName() string })
+	if !ok {
+		return err
+	}
+
+	d, exists := drivers[dn.DriverName()]
+	if !exists {
+		return err
+	}
+
+	code, ok := d.Code(err)
+	if !ok {
+		return err
+	}
+
+	if sentinel, exists := codes[code]; exists {
+		return &dbError{sentinel: sentinel, err: err}
+	}
+
+	return err
+}
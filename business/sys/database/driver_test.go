@@ -0,0 +1,60 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+type namedDB string
+
+func (n namedDB) DriverName() string {
+	return string(n)
+}
+
+func Test_ToError(t *testing.T) {
+	tt := []struct {
+		name   string
+		driver string
+		err    error
+		exp    error
+	}{
+		{"pgx-unique", DriverPgx, &pgconn.PgError{Code: "23505"}, ErrDBDuplicatedEntry},
+		{"pgx-fk", DriverPgx, &pgconn.PgError{Code: "23503"}, ErrForeignKeyViolation},
+		{"pgx-wrapped", DriverPgx, fmt.Errorf("exec: %w", &pgconn.PgError{Code: "42P01"}), ErrUndefinedTable},
+		{"pq-unique", DriverPq, &pq.Error{Code: "23505"}, ErrDBDuplicatedEntry},
+		{"pq-serialization", DriverPq, &pq.Error{Code: "40001"}, ErrSerializationFailure},
+	}
+
+	for _, tst := range tt {
+		t.Run(tst.name, func(t *testing.T) {
+			err := toError(namedDB(tst.driver), tst.err)
+			if !errors.Is(err, tst.exp) {
+				t.Logf("got: %v", err)
+				t.Logf("exp: %v", tst.exp)
+				t.Fatalf("Should map the driver error to the error variable.")
+			}
+
+			if !errors.Is(err, tst.err) {
+				t.Fatalf("Should keep the driver error in the chain.")
+			}
+
+			if err := toError(namedDB(tst.driver), err); !errors.Is(err, tst.exp) {
+				t.Fatalf("Should be able to map an error more than once.")
+			}
+		})
+	}
+
+	unknown := &pgconn.PgError{Code: "22001"}
+	if err := toError(namedDB(DriverPgx), unknown); err != unknown {
+		t.Logf("got: %v", err)
+		t.Fatalf("Should return unknown codes as is.")
+	}
+
+	if err := toError(namedDB(DriverPq), &pgconn.PgError{Code: "23505"}); errors.Is(err, ErrDBDuplicatedEntry) {
+		t.Fatalf("Should only map errors of the driver in use.")
+	}
+}
@@ -0,0 +1,203 @@
+// Package querylog formats database queries for logs and span attributes,
+// masking the values of sensitive parameters.
+package querylog
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+// Mask replaces the value of a sensitive parameter.
+const Mask = "'*****'"
+
+// SensitiveOption is the db tag option that marks a field as sensitive, as
+// in `db:"password_hash,sensitive"`.
+const SensitiveOption = "sensitive"
+
+// Mode represents how the parameters of a query are written.
+type Mode int32
+
+// Set of query logging modes.
+const (
+	// Redacted writes the parameter values, masking the sensitive ones.
+	Redacted Mode = iota
+
+	// Full writes every parameter value.
+	Full
+
+	// Parameterless writes the query with its named parameters and no values.
+	Parameterless
+)
+
+// ParseMode parses the name of a mode: full, redacted or parameterless.
+func ParseMode(name string) (Mode, error) {
+	switch name {
+	case "redacted":
+		return Redacted, nil
+	case "full":
+		return Full, nil
+	case "parameterless":
+		return Parameterless, nil
+	}
+
+	return Redacted, fmt.Errorf("unknown query log mode %q", name)
+}
+
+// String implements the Stringer interface.
+func (m Mode) String() string {
+	switch m {
+	case Full:
+		return "full"
+	case Parameterless:
+		return "parameterless"
+	}
+	return "redacted"
+}
+
+// =============================================================================
+
+var (
+	mode   int32
+	mu     sync.RWMutex
+	names  = make(map[string]bool)
+	mapper = reflectx.NewMapperFunc("db", sqlx.NameMapper)
+)
+
+// SetMode sets the mode used to format every query.
+func SetMode(m Mode) {
+	atomic.StoreInt32(&mode, int32(m))
+}
+
+// Sensitive registers parameter names whose values are always masked. This
+// covers parameters bound from maps, which have no tags to mark them.
+func Sensitive(paramNames ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, name := range paramNames {
+		names[name] = true
+	}
+}
+
+func isRegistered(name string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return names[name]
+}
+
+// =============================================================================
+
+// String provides a pretty print version of the query and its parameters
+// formatted according to the current mode.
+func String(query string, args any) string {
+	m := Mode(atomic.LoadInt32(&mode))
+
+	if m == Parameterless {
+		return clean(query)
+	}
+
+	bound, params, err := sqlx.Named(query, args)
+	if err != nil {
+		return err.Error()
+	}
+
+	// The parameter names are only needed to find the sensitive values. If
+	// they can't be matched to the values, every value is masked.
+	var paramNames []string
+	var sensitive map[string]bool
+	if m == Redacted {
+		paramNames = parseNames(query)
+		sensitive = sensitiveFields(args)
+	}
+
+	for i, param := range params {
+		var value string
+
+		switch {
+		case m == Redacted && (len(paramNames) != len(params) || sensitive[paramNames[i]] || isRegistered(paramNames[i])):
+			value = Mask
+
+		default:
+			switch v := param.(type) {
+			case string:
+				value = fmt.Sprintf("'%s'", v)
+			case []byte:
+				value = fmt.Sprintf("'%s'", string(v))
+			default:
+				value = fmt.Sprintf("%v", v)
+			}
+		}
+
+		bound = strings.Replace(bound, "?", value, 1)
+	}
+
+	return clean(bound)
+}
+
+// clean removes the formatting whitespace from a query.
+func clean(query string) string {
+	query = strings.ReplaceAll(query, "\t", "")
+	query = strings.ReplaceAll(query, "\n", " ")
+
+	return strings.Trim(query, " ")
+}
+
+// parseNames returns the named parameters of the query in the order they are
+// bound, following the same rules as sqlx where :: is an escaped colon.
+func parseNames(query string) []string {
+	var paramNames []string
+
+	for i := 0; i < len(query); i++ {
+		if query[i] != ':' {
+			continue
+		}
+
+		if i+1 < len(query) && query[i+1] == ':' {
+			i++
+			continue
+		}
+
+		j := i + 1
+		for j < len(query) && isNameChar(query[j]) {
+			j++
+		}
+		if j > i+1 {
+			paramNames = append(paramNames, query[i+1:j])
+		}
+		i = j - 1
+	}
+
+	return paramNames
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// sensitiveFields returns the names of the struct fields marked with the
+// sensitive db tag option.
+func sensitiveFields(args any) map[string]bool {
+	t := reflect.TypeOf(args)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := make(map[string]bool)
+	for name, fi := range mapper.TypeMap(t).Names {
+		if _, exists := fi.Options[SensitiveOption]; exists {
+			fields[name] = true
+		}
+	}
+
+	return fields
+}
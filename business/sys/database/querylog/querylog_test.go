@@ -0,0 +1,74 @@
+package querylog_test
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/service/business/sys/database/querylog"
+)
+
+func Test_String(t *testing.T) {
+	querylog.Sensitive("token")
+
+	type user struct {
+		Name         string `db:"name"`
+		PasswordHash []byte `db:"password_hash,sensitive"`
+	}
+
+	const q = `
+	UPDATE users SET
+		name = :name,
+		password_hash = :password_hash
+	WHERE
+		date_created > '2023-01-01'::date`
+
+	tt := []struct {
+		name  string
+		mode  querylog.Mode
+		query string
+		args  any
+		exp   string
+	}{
+		{
+			name:  "redacted",
+			mode:  querylog.Redacted,
+			query: q,
+			args:  user{Name: "Bill", PasswordHash: []byte("hash")},
+			exp:   `UPDATE users SET name = 'Bill', password_hash = '*****' WHERE date_created > '2023-01-01':date`,
+		},
+		{
+			name:  "full",
+			mode:  querylog.Full,
+			query: q,
+			args:  user{Name: "Bill", PasswordHash: []byte("hash")},
+			exp:   `UPDATE users SET name = 'Bill', password_hash = 'hash' WHERE date_created > '2023-01-01':date`,
+		},
+		{
+			name:  "parameterless",
+			mode:  querylog.Parameterless,
+			query: q,
+			args:  user{Name: "Bill", PasswordHash: []byte("hash")},
+			exp:   `UPDATE users SET name = :name, password_hash = :password_hash WHERE date_created > '2023-01-01'::date`,
+		},
+		{
+			name:  "registry",
+			mode:  querylog.Redacted,
+			query: `SELECT * FROM keys WHERE token = :token AND id = :id`,
+			args:  map[string]any{"token": "secret", "id": 10},
+			exp:   `SELECT * FROM keys WHERE token = '*****' AND id = 10`,
+		},
+	}
+
+	for _, tst := range tt {
+		t.Run(tst.name, func(t *testing.T) {
+			querylog.SetMode(tst.mode)
+			defer querylog.SetMode(querylog.Redacted)
+
+			got := querylog.String(tst.query, tst.args)
+			if got != tst.exp {
+				t.Logf("got: %v", got)
+				t.Logf("exp: %v", tst.exp)
+				t.Error("Should get the expected query")
+			}
+		})
+	}
+}
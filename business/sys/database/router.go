@@ -0,0 +1,187 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Set of context keys for routing the calls between the primary and the
+// replicas.
+const (
+	stickyKey  ctxKey = 2
+	primaryKey ctxKey = 3
+)
+
+// DB represents the primary database and its read replicas. The helpers in
+// this package send the queries to a healthy replica and everything else to
+// the primary. A query runs against the primary when it's made inside a
+// transaction, with a context from WithPrimary, or with a context from
+// WithStickiness after a write was made with it.
+type DB struct {
+	*sqlx.DB
+	replicas []*replica
+	next     uint32
+	shutdown chan struct{}
+}
+
+// replica represents a read replica. An unhealthy replica doesn't receive
+// queries until a health check succeeds.
+type replica struct {
+	db      *sqlx.DB
+	host    string
+	healthy int32
+}
+
+// Close closes the primary and the replicas and stops the health checks.
+func (db *DB) Close() error {
+	close(db.shutdown)
+
+	for _, r := range db.replicas {
+		r.db.Close()
+	}
+
+	return db.DB.Close()
+}
+
+// StartHealthCheck pings the replicas at the specified interval until the DB
+// is closed. A replica that fails a ping is evicted, and one that succeeds is
+// put back in rotation. The interval must be above zero when there are
+// replicas, since an evicted replica is only put back by a health check.
+func (db *DB) StartHealthCheck(log *zap.SugaredLogger, interval time.Duration) error {
+	if len(db.replicas) == 0 {
+		return nil
+	}
+
+	if interval <= 0 {
+		return fmt.Errorf("health check interval must be above zero: %s", interval)
+	}
+
+	log = log.Named("database")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				for _, r := range db.replicas {
+					db.checkReplica(log, r, interval)
+				}
+			case <-db.shutdown:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (db *DB) checkReplica(log *zap.SugaredLogger, r *replica, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := r.db.PingContext(ctx); err != nil {
+		if atomic.SwapInt32(&r.healthy, 0) == 1 {
+			log.Errorw("replica evicted", "host", r.host, "ERROR", err)
+		}
+		return
+	}
+
+	if atomic.SwapInt32(&r.healthy, 1) == 0 {
+		log.Infow("replica restored", "host", r.host)
+	}
+}
+
+// replica returns the next healthy replica in rotation.
+func (db *DB) replica() (*replica, bool) {
+	n := uint32(len(db.replicas))
+	start := atomic.AddUint32(&db.next, 1)
+
+	for i := uint32(0); i < n; i++ {
+		r := db.replicas[(start+i)%n]
+		if atomic.LoadInt32(&r.healthy) == 1 {
+			return r, true
+		}
+	}
+
+	return nil, false
+}
+
+// evict takes the replica out of rotation until a health check succeeds.
+func (r *replica) evict(log *zap.SugaredLogger, err error) {
+	if atomic.SwapInt32(&r.healthy, 0) == 1 {
+		log.Errorw("replica evicted", "host", r.host, "ERROR", err)
+	}
+}
+
+// =============================================================================
+
+// sticky records if a write was made with the context it's carried by.
+type sticky struct {
+	wrote int32
+}
+
+// WithStickiness returns a context that sends the queries to the primary once
+// a write was made with it, so the writes are visible to the following reads.
+// It's meant to be used once per request.
+func WithStickiness(ctx context.Context) context.Context {
+	return context.WithValue(ctx, stickyKey, &sticky{})
+}
+
+// WithPrimary returns a context that sends the queries to the primary. Use it
+// for queries that write, like an INSERT with a RETURNING clause, or that
+// can't tolerate the replication lag.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey, true)
+}
+
+// markWrite records a write made with the context.
+func markWrite(ctx context.Context) {
+	if s, ok := ctx.Value(stickyKey).(*sticky); ok {
+		atomic.StoreInt32(&s.wrote, 1)
+	}
+}
+
+// onPrimary reports whether the queries made with the context must be sent to
+// the primary.
+func onPrimary(ctx context.Context) bool {
+	if _, ok := ctx.Value(primaryKey).(bool); ok {
+		return true
+	}
+
+	if s, ok := ctx.Value(stickyKey).(*sticky); ok && atomic.LoadInt32(&s.wrote) == 1 {
+		return true
+	}
+
+	return false
+}
+
+// reader returns the connection to run a query with and the replica it
+// belongs to, if any.
+func reader(ctx context.Context, db sqlx.ExtContext) (sqlx.ExtContext, *replica) {
+	db = conn(ctx, db)
+
+	pdb, ok := db.(*DB)
+	if !ok {
+		return db, nil
+	}
+
+	// A query sent to the primary may be a write, so it's recorded as one.
+	if onPrimary(ctx) {
+		markWrite(ctx)
+		return pdb, nil
+	}
+
+	r, ok := pdb.replica()
+	if !ok {
+		return pdb, nil
+	}
+
+	return r.db, r
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+func Test_Reader(t *testing.T) {
+	r1 := replica{db: &sqlx.DB{}, host: "r1", healthy: 1}
+	r2 := replica{db: &sqlx.DB{}, host: "r2", healthy: 1}
+	db := DB{
+		DB:       &sqlx.DB{},
+		replicas: []*replica{&r1, &r2},
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < 4; i++ {
+		_, r := reader(context.Background(), &db)
+		if r == nil {
+			t.Fatalf("Should send the query to a replica.")
+		}
+		seen[r.host] = true
+	}
+	if len(seen) != 2 {
+		t.Logf("got: %v", seen)
+		t.Fatalf("Should rotate between the replicas.")
+	}
+
+	r1.evict(zap.NewNop().Sugar(), errors.New("down"))
+	for i := 0; i < 4; i++ {
+		if _, r := reader(context.Background(), &db); r != &r2 {
+			t.Fatalf("Should NOT send the query to an evicted replica.")
+		}
+	}
+
+	r2.evict(zap.NewNop().Sugar(), errors.New("down"))
+	if conn, r := reader(context.Background(), &db); r != nil || conn != sqlx.ExtContext(&db) {
+		t.Fatalf("Should send the query to the primary without healthy replicas.")
+	}
+}
+
+func Test_ReaderSticky(t *testing.T) {
+	r1 := replica{db: &sqlx.DB{}, host: "r1", healthy: 1}
+	db := DB{
+		DB:       &sqlx.DB{},
+		replicas: []*replica{&r1},
+	}
+
+	ctx := WithStickiness(context.Background())
+	if _, r := reader(ctx, &db); r == nil {
+		t.Fatalf("Should send the query to a replica before a write.")
+	}
+
+	markWrite(ctx)
+	if _, r := reader(ctx, &db); r != nil {
+		t.Fatalf("Should send the query to the primary after a write.")
+	}
+
+	if _, r := reader(context.Background(), &db); r == nil {
+		t.Fatalf("Should only stick the context the write was made with.")
+	}
+
+	if _, r := reader(WithPrimary(context.Background()), &db); r != nil {
+		t.Fatalf("Should send the query to the primary when asked to.")
+	}
+
+	tx := sqlx.Tx{}
+	ctx = context.WithValue(context.Background(), tranKey, &tran{db: &db, tx: &tx})
+	if conn, r := reader(ctx, &db); r != nil || conn != sqlx.ExtContext(&tx) {
+		t.Fatalf("Should send the query to the transaction it's made in.")
+	}
+}
+
+func Test_StartHealthCheck(t *testing.T) {
+	db := DB{
+		DB:       &sqlx.DB{},
+		replicas: []*replica{{db: &sqlx.DB{}, host: "r1", healthy: 1}},
+		shutdown: make(chan struct{}),
+	}
+
+	if err := db.StartHealthCheck(zap.NewNop().Sugar(), 0); err == nil {
+		t.Fatalf("Should NOT start a health check without an interval.")
+	}
+
+	db.replicas = nil
+	if err := db.StartHealthCheck(zap.NewNop().Sugar(), 0); err != nil {
+		t.Fatalf("Should NOT need an interval without replicas : %s", err)
+	}
+}
@@ -0,0 +1,151 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	sqldriver "database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"syscall"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type ctxKey int
+
+const tranKey ctxKey = 1
+
+// tran is the transaction carried in the context by WithinTran. The depth is
+// the number of savepoints the transaction is nested in.
+type tran struct {
+	db    *DB
+	tx    *sqlx.Tx
+	depth int
+}
+
+// conn returns the transaction carried in the context when it was started
+// on db, otherwise db is returned.
+func conn(ctx context.Context, db sqlx.ExtContext) sqlx.ExtContext {
+	t, ok := ctx.Value(tranKey).(*tran)
+	if !ok || db != sqlx.ExtContext(t.db) {
+		return db
+	}
+
+	return t.tx
+}
+
+// withinSavepoint runs the function inside a savepoint of the transaction. The
+// savepoint is released on success and rolled back on error.
+func withinSavepoint(ctx context.Context, log *zap.SugaredLogger, t *tran, fn func(ctx context.Context) error) error {
+	nested := tran{
+		db:    t.db,
+		tx:    t.tx,
+		depth: t.depth + 1,
+	}
+	savepoint := fmt.Sprintf("sp_%d", nested.depth)
+
+	log.Infow("begin savepoint", "savepoint", savepoint)
+	if _, err := t.tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return fmt.Errorf("begin savepoint: %w", toError(t.tx, err))
+	}
+
+	if err := fn(context.WithValue(ctx, tranKey, &nested)); err != nil {
+		if _, err := t.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint); err != nil {
+			log.Errorw("unable to rollback savepoint", "savepoint", savepoint, "ERROR", err)
+		}
+		log.Infow("rollback savepoint", "savepoint", savepoint)
+		return fmt.Errorf("exec savepoint: %w", toError(t.tx, err))
+	}
+
+	if _, err := t.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint); err != nil {
+		return fmt.Errorf("release savepoint: %w", toError(t.tx, err))
+	}
+	log.Infow("release savepoint", "savepoint", savepoint)
+
+	return nil
+}
+
+// =============================================================================
+
+// DefaultRetries is the number of times a transaction runs again by default.
+const DefaultRetries = 3
+
+// Set of bounds for the delay between the runs of a transaction.
+const (
+	baseDelay = 10 * time.Millisecond
+	maxDelay  = time.Second
+)
+
+// TranOptions represent optional parameters for a transaction.
+type TranOptions struct {
+	isolation sql.IsolationLevel
+	readOnly  bool
+	retries   int
+}
+
+// WithIsolation sets the isolation level of the transaction. The default
+// level of the database is used otherwise.
+func WithIsolation(level sql.IsolationLevel) func(opts *TranOptions) {
+	return func(opts *TranOptions) {
+		opts.isolation = level
+	}
+}
+
+// WithReadOnly starts the transaction in read only mode.
+func WithReadOnly() func(opts *TranOptions) {
+	return func(opts *TranOptions) {
+		opts.readOnly = true
+	}
+}
+
+// WithRetries sets the number of times the transaction runs again after a
+// retryable error. Zero disables the retries.
+func WithRetries(retries int) func(opts *TranOptions) {
+	return func(opts *TranOptions) {
+		opts.retries = retries
+	}
+}
+
+// conflict reports whether the error is a conflict with a concurrent
+// transaction, which leaves nothing applied.
+func conflict(err error) bool {
+	return errors.Is(err, ErrSerializationFailure) || errors.Is(err, ErrDeadlock)
+}
+
+// retryable reports whether a transaction that failed with the error can run
+// again.
+func retryable(err error) bool {
+	return conflict(err) || connLost(err)
+}
+
+// connLost reports whether the error means the connection to the database
+// was lost, as opposed to the statement failing.
+func connLost(err error) bool {
+	switch {
+	case errors.Is(err, ErrConnectionFailure),
+		errors.Is(err, sqldriver.ErrBadConn),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	}
+
+	return false
+}
+
+// backoff returns the delay before the next run of a transaction after the
+// specified attempt. The delay is a random value up to an exponential bound,
+// which spreads out the transactions that conflicted with each other.
+func backoff(attempt int) time.Duration {
+	bound := maxDelay
+	if attempt < 16 {
+		if d := baseDelay << attempt; d < maxDelay {
+			bound = d
+		}
+	}
+
+	return time.Duration(rand.Int63n(int64(bound)))
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"syscall"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func Test_Retryable(t *testing.T) {
+	tt := []struct {
+		name string
+		err  error
+		exp  bool
+	}{
+		{"serialization", toError(namedDB(DriverPgx), &pgconn.PgError{Code: "40001"}), true},
+		{"deadlock", toError(namedDB(DriverPgx), &pgconn.PgError{Code: "40P01"}), true},
+		{"connection", toError(namedDB(DriverPgx), &pgconn.PgError{Code: "08006"}), true},
+		{"reset", fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{"unique", toError(namedDB(DriverPgx), &pgconn.PgError{Code: "23505"}), false},
+		{"not-found", sql.ErrNoRows, false},
+	}
+
+	for _, tst := range tt {
+		t.Run(tst.name, func(t *testing.T) {
+			if got := retryable(tst.err); got != tst.exp {
+				t.Logf("got: %v", got)
+				t.Logf("exp: %v", tst.exp)
+				t.Fatalf("Should know if the error can be retried.")
+			}
+		})
+	}
+
+	if conflict(ErrConnectionFailure) {
+		t.Fatalf("Should NOT treat a connection failure as a conflict.")
+	}
+
+	if !conflict(fmt.Errorf("commit tran: %w", ErrSerializationFailure)) {
+		t.Fatalf("Should treat a serialization failure as a conflict.")
+	}
+
+	if connLost(toError(namedDB(DriverPgx), &pgconn.PgError{Code: "40001"})) {
+		t.Fatalf("Should NOT treat a recovery conflict as a lost connection.")
+	}
+
+	if !connLost(fmt.Errorf("read: %w", syscall.ECONNRESET)) {
+		t.Fatalf("Should treat a reset connection as a lost connection.")
+	}
+}
+
+func Test_Backoff(t *testing.T) {
+	for attempt := 0; attempt < 100; attempt++ {
+		bound := maxDelay
+		if attempt < 7 {
+			bound = baseDelay << attempt
+		}
+
+		for i := 0; i < 100; i++ {
+			if d := backoff(attempt); d < 0 || d >= bound {
+				t.Logf("got: %v", d)
+				t.Logf("exp: [0, %v)", bound)
+				t.Fatalf("Should keep the delay for attempt %d within the bound.", attempt)
+			}
+		}
+	}
+}
@@ -5,6 +5,7 @@ package auth
 
 import (
 	"context"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"strings"
@@ -13,9 +14,9 @@ import (
 	"github.com/ardanlabs/service/business/core/event"
 	"github.com/ardanlabs/service/business/core/user"
 	"github.com/ardanlabs/service/business/core/user/stores/userdb"
+	"github.com/ardanlabs/service/business/sys/database"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
-	"github.com/jmoiron/sqlx"
 	"github.com/open-policy-agent/opa/rego"
 	"go.uber.org/zap"
 )
@@ -40,9 +41,13 @@ type KeyLookup interface {
 // Config represents information required to initialize auth.
 type Config struct {
 	Log       *zap.SugaredLogger
-	DB        *sqlx.DB
+	DB        *database.DB
 	KeyLookup KeyLookup
 	Issuer    string
+
+	// CertRoles maps the common name of the client certificates of internal
+	// callers to the roles they are given when authenticating with mutual TLS.
+	CertRoles map[string][]user.Role
 }
 
 // Auth is used to authenticate clients. It can generate a token for a
@@ -54,6 +59,7 @@ type Auth struct {
 	method    jwt.SigningMethod
 	parser    *jwt.Parser
 	issuer    string
+	certRoles map[string][]user.Role
 	mu        sync.RWMutex
 	cache     map[string]string
 }
@@ -76,6 +82,7 @@ func New(cfg Config) (*Auth, error) {
 		method:    jwt.GetSigningMethod(jwt.SigningMethodRS256.Name),
 		parser:    jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Name})),
 		issuer:    cfg.Issuer,
+		certRoles: cfg.CertRoles,
 		cache:     make(map[string]string),
 	}
 
@@ -154,6 +161,31 @@ func (a *Auth) Authenticate(ctx context.Context, bearerToken string) (Claims, er
 	return claims, nil
 }
 
+// AuthenticateCert maps a client certificate, already verified during the TLS
+// handshake, into claims. The common name of the certificate is the subject
+// and the roles are those configured for it. Certificates of callers that
+// are not configured are rejected.
+func (a *Auth) AuthenticateCert(cert *x509.Certificate) (Claims, error) {
+	cn := cert.Subject.CommonName
+
+	roles, exists := a.certRoles[cn]
+	if !exists || cn == "" {
+		return Claims{}, fmt.Errorf("no roles for client certificate %q", cn)
+	}
+
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   cn,
+			Issuer:    a.issuer,
+			ExpiresAt: jwt.NewNumericDate(cert.NotAfter),
+			IssuedAt:  jwt.NewNumericDate(cert.NotBefore),
+		},
+		Roles: roles,
+	}
+
+	return claims, nil
+}
+
 // Authorize attempts to authorize the user with the provided input roles, if
 // none of the input roles are within the user's claims, we return an error
 // otherwise the user is authorized.
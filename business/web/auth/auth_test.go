@@ -4,16 +4,18 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"fmt"
 	"runtime/debug"
 	"testing"
 	"time"
 
 	"github.com/ardanlabs/service/business/core/user"
+	"github.com/ardanlabs/service/business/sys/database"
 	"github.com/ardanlabs/service/business/web/auth"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
-	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -233,7 +235,7 @@ func Test_Auth(t *testing.T) {
 
 // =============================================================================
 
-func newUnit(t *testing.T) (*zap.SugaredLogger, *sqlx.DB, func()) {
+func newUnit(t *testing.T) (*zap.SugaredLogger, *database.DB, func()) {
 	var buf bytes.Buffer
 	encoder := zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
 	writer := bufio.NewWriter(&buf)
@@ -314,3 +316,68 @@ vwIDAQAB
 -----END PUBLIC KEY-----
 `
 )
+
+// Test_AuthenticateCert validates client certificates are mapped to the
+// claims of their configured roles.
+func Test_AuthenticateCert(t *testing.T) {
+	t.Parallel()
+
+	cfg := auth.Config{
+		Log:       zap.NewNop().Sugar(),
+		KeyLookup: &keyStore{},
+		Issuer:    "service project",
+		CertRoles: map[string][]user.Role{
+			"billing": {user.RoleAdmin, user.RoleUser},
+		},
+	}
+	a, err := auth.New(cfg)
+	if err != nil {
+		t.Fatalf("Should be able to create an authenticator: %s", err)
+	}
+
+	notBefore := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := notBefore.Add(24 * time.Hour)
+
+	cert := x509.Certificate{
+		Subject:   pkix.Name{CommonName: "billing"},
+		NotBefore: notBefore,
+		NotAfter:  notAfter,
+	}
+
+	claims, err := a.AuthenticateCert(&cert)
+	if err != nil {
+		t.Fatalf("Should be able to authenticate a configured certificate : %s", err)
+	}
+
+	if claims.Subject != "billing" {
+		t.Logf("got: %v", claims.Subject)
+		t.Logf("exp: %v", "billing")
+		t.Error("Should use the common name as the subject")
+	}
+
+	if claims.Issuer != cfg.Issuer {
+		t.Logf("got: %v", claims.Issuer)
+		t.Logf("exp: %v", cfg.Issuer)
+		t.Error("Should use the configured issuer")
+	}
+
+	if !claims.ExpiresAt.Equal(notAfter) || !claims.IssuedAt.Equal(notBefore) {
+		t.Logf("got: %v %v", claims.IssuedAt, claims.ExpiresAt)
+		t.Logf("exp: %v %v", notBefore, notAfter)
+		t.Error("Should use the validity of the certificate")
+	}
+
+	exp := cfg.CertRoles["billing"]
+	if len(claims.Roles) != len(exp) || !claims.Roles[0].Equal(exp[0]) || !claims.Roles[1].Equal(exp[1]) {
+		t.Logf("got: %v", claims.Roles)
+		t.Logf("exp: %v", exp)
+		t.Error("Should have the configured roles")
+	}
+
+	for _, cn := range []string{"reports", ""} {
+		cert := x509.Certificate{Subject: pkix.Name{CommonName: cn}}
+		if _, err := a.AuthenticateCert(&cert); err == nil {
+			t.Errorf("Should NOT be able to authenticate the certificate %q", cn)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Package idempotency provides support for replaying the response of a
+// request that is retried with the same idempotency key.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Set of error variables for idempotency keys.
+var (
+	ErrNotFound   = errors.New("idempotency key not found")
+	ErrExists     = errors.New("idempotency key already exists")
+	ErrMismatch   = errors.New("idempotency key was used with a different request")
+	ErrInProgress = errors.New("a request with this idempotency key is in progress")
+	ErrClaimLost  = errors.New("idempotency key is no longer claimed by the request")
+)
+
+// MaxKeyLength is the longest idempotency key that is accepted.
+const MaxKeyLength = 255
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve the records.
+type Storer interface {
+	Create(ctx context.Context, rec Record) error
+	Update(ctx context.Context, rec Record) error
+	Delete(ctx context.Context, rec Record) error
+	QueryByKey(ctx context.Context, subject string, key string) (Record, error)
+	DeleteExpired(ctx context.Context, now time.Time) error
+}
+
+// =============================================================================
+
+// Record represents a request made with an idempotency key and, once the
+// request has completed, the response that was sent. A status of zero
+// means the request is still in progress, in which case the record expires
+// when the claim on the key times out.
+type Record struct {
+	Subject     string
+	Key         string
+	Fingerprint string
+	Status      int
+	Body        []byte
+	DateCreated time.Time
+	DateExpires time.Time
+}
+
+// Fingerprint returns a value identifying the request so a key being reused
+// for a different request can be detected.
+func Fingerprint(method string, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// =============================================================================
+
+// Core manages the set of APIs for idempotency key access.
+type Core struct {
+	storer      Storer
+	ttl         time.Duration
+	lockTimeout time.Duration
+}
+
+// NewCore constructs a core for idempotency key api access. Completed
+// records are kept for the specified time to live. A key claimed by a
+// request that never completes, such as when the process crashes, is
+// released after the lock timeout so the request can be retried. The lock
+// timeout must be longer than a request can take.
+func NewCore(storer Storer, ttl time.Duration, lockTimeout time.Duration) *Core {
+	return &Core{
+		storer:      storer,
+		ttl:         ttl,
+		lockTimeout: lockTimeout,
+	}
+}
+
+// Begin looks for a record for the subject and key. When a completed record
+// exists for the same request it is returned with replay set to true. When
+// there is no record, one is created to claim the key for this request.
+func (c *Core) Begin(ctx context.Context, subject string, key string, fingerprint string) (rec Record, replay bool, err error) {
+	// The creation date identifies the claim, so it's kept at the precision
+	// the database stores.
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	rec, err = c.storer.QueryByKey(ctx, subject, key)
+	switch {
+	case err == nil && now.Before(rec.DateExpires):
+		if rec.Fingerprint != fingerprint {
+			return Record{}, false, ErrMismatch
+		}
+		if rec.Status == 0 {
+			return Record{}, false, ErrInProgress
+		}
+		return rec, true, nil
+
+	case err != nil && !errors.Is(err, ErrNotFound):
+		return Record{}, false, fmt.Errorf("query: subject[%s] key[%s]: %w", subject, key, err)
+	}
+
+	rec = Record{
+		Subject:     subject,
+		Key:         key,
+		Fingerprint: fingerprint,
+		DateCreated: now,
+		DateExpires: now.Add(c.lockTimeout),
+	}
+
+	if err := c.storer.Create(ctx, rec); err != nil {
+		if errors.Is(err, ErrExists) {
+			return Record{}, false, ErrInProgress
+		}
+		return Record{}, false, fmt.Errorf("create: %w", err)
+	}
+
+	return rec, false, nil
+}
+
+// Complete stores the response for the request that claimed the key. The
+// record is kept from then on for the time to live. ErrClaimLost is returned
+// when the claim timed out and the key was claimed again by a retry.
+func (c *Core) Complete(ctx context.Context, rec Record, status int, body []byte) error {
+	rec.Status = status
+	rec.Body = body
+	rec.DateExpires = rec.DateCreated.Add(c.ttl)
+
+	if err := c.storer.Update(ctx, rec); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// Release removes the claim on the key when the request failed, so the
+// request can be retried with the same key. A claim that timed out and was
+// taken by a retry is left alone.
+func (c *Core) Release(ctx context.Context, rec Record) error {
+	if err := c.storer.Delete(ctx, rec); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// StartPrune deletes the expired records at the specified interval until the
+// returned function is called. Each prune is given the interval to complete.
+func (c *Core) StartPrune(log *zap.SugaredLogger, interval time.Duration) (stop func()) {
+	shutdown := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.prune(log, interval)
+			case <-shutdown:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(shutdown)
+		<-done
+	}
+}
+
+func (c *Core) prune(log *zap.SugaredLogger, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := c.storer.DeleteExpired(ctx, time.Now().UTC()); err != nil {
+		log.Errorw("idempotency", "status", "prune failed", "ERROR", err)
+	}
+}
@@ -0,0 +1,166 @@
+package idempotency_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/web/idempotency"
+)
+
+// Test_LockTimeout validates a key claimed by a request that never completes
+// is released after the lock timeout, while completed keys are kept.
+func Test_LockTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	const lockTimeout = 20 * time.Millisecond
+
+	core := idempotency.NewCore(newStore(), time.Hour, lockTimeout)
+
+	if _, _, err := core.Begin(ctx, "sub", "abandoned", "fp"); err != nil {
+		t.Fatalf("Should be able to claim the key : %s", err)
+	}
+
+	if _, _, err := core.Begin(ctx, "sub", "abandoned", "fp"); !errors.Is(err, idempotency.ErrInProgress) {
+		t.Fatalf("Should get an in progress error while the key is claimed : %v", err)
+	}
+
+	time.Sleep(2 * lockTimeout)
+
+	if _, replay, err := core.Begin(ctx, "sub", "abandoned", "fp"); err != nil || replay {
+		t.Fatalf("Should be able to claim the key once the claim timed out : %v", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	rec, _, err := core.Begin(ctx, "sub", "completed", "fp")
+	if err != nil {
+		t.Fatalf("Should be able to claim the key : %s", err)
+	}
+
+	if err := core.Complete(ctx, rec, 201, []byte("{}")); err != nil {
+		t.Fatalf("Should be able to complete the request : %s", err)
+	}
+
+	time.Sleep(2 * lockTimeout)
+
+	if _, replay, err := core.Begin(ctx, "sub", "completed", "fp"); err != nil || !replay {
+		t.Fatalf("Should replay a completed request after the lock timeout : %v", err)
+	}
+}
+
+// Test_ClaimLost validates a request whose claim timed out can neither
+// complete nor release the claim taken by a retry.
+func Test_ClaimLost(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	const lockTimeout = 20 * time.Millisecond
+
+	core := idempotency.NewCore(newStore(), time.Hour, lockTimeout)
+
+	first, _, err := core.Begin(ctx, "sub", "key", "fp")
+	if err != nil {
+		t.Fatalf("Should be able to claim the key : %s", err)
+	}
+
+	time.Sleep(2 * lockTimeout)
+
+	retry, _, err := core.Begin(ctx, "sub", "key", "fp")
+	if err != nil {
+		t.Fatalf("Should be able to claim the key once the claim timed out : %s", err)
+	}
+
+	if err := core.Release(ctx, first); err != nil {
+		t.Fatalf("Should be able to release a lost claim : %s", err)
+	}
+
+	if _, _, err := core.Begin(ctx, "sub", "key", "fp"); !errors.Is(err, idempotency.ErrInProgress) {
+		t.Fatalf("Should keep the claim of the retry when a lost claim is released : %v", err)
+	}
+
+	if err := core.Complete(ctx, first, 201, []byte("{}")); !errors.Is(err, idempotency.ErrClaimLost) {
+		t.Fatalf("Should NOT be able to complete a lost claim : %v", err)
+	}
+
+	if err := core.Complete(ctx, retry, 201, []byte("{}")); err != nil {
+		t.Fatalf("Should be able to complete the claim of the retry : %s", err)
+	}
+}
+
+// =============================================================================
+
+type store struct {
+	mu   sync.Mutex
+	recs map[string]idempotency.Record
+}
+
+func newStore() *store {
+	return &store{
+		recs: make(map[string]idempotency.Record),
+	}
+}
+
+func (s *store) Create(ctx context.Context, rec idempotency.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cur, exists := s.recs[rec.Subject+rec.Key]; exists && cur.DateExpires.After(rec.DateCreated) {
+		return idempotency.ErrExists
+	}
+	s.recs[rec.Subject+rec.Key] = rec
+
+	return nil
+}
+
+func (s *store) Update(ctx context.Context, rec idempotency.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cur, exists := s.recs[rec.Subject+rec.Key]
+	if !exists || !cur.DateCreated.Equal(rec.DateCreated) {
+		return idempotency.ErrClaimLost
+	}
+	s.recs[rec.Subject+rec.Key] = rec
+
+	return nil
+}
+
+func (s *store) Delete(ctx context.Context, rec idempotency.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cur, exists := s.recs[rec.Subject+rec.Key]; exists && cur.DateCreated.Equal(rec.DateCreated) {
+		delete(s.recs, rec.Subject+rec.Key)
+	}
+
+	return nil
+}
+
+func (s *store) QueryByKey(ctx context.Context, subject string, key string) (idempotency.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, exists := s.recs[subject+key]
+	if !exists {
+		return idempotency.Record{}, idempotency.ErrNotFound
+	}
+
+	return rec, nil
+}
+
+func (s *store) DeleteExpired(ctx context.Context, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k, rec := range s.recs {
+		if !rec.DateExpires.After(now) {
+			delete(s.recs, k)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+// Package idempotencydb contains idempotency key related CRUD functionality.
+package idempotencydb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/sys/database"
+	"github.com/ardanlabs/service/business/web/idempotency"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for idempotency key database access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  *database.DB
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *zap.SugaredLogger, db *database.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts a new record into the database. An expired record for the
+// same subject and key is replaced.
+func (s *Store) Create(ctx context.Context, rec idempotency.Record) error {
+	const q = `
+	INSERT INTO idempotency_keys
+		(subject, idempotency_key, fingerprint, status, body, date_created, date_expires)
+	VALUES
+		(:subject, :idempotency_key, :fingerprint, :status, :body, :date_created, :date_expires)
+	ON CONFLICT (subject, idempotency_key) DO UPDATE SET
+		"fingerprint" = EXCLUDED.fingerprint,
+		"status" = EXCLUDED.status,
+		"body" = EXCLUDED.body,
+		"date_created" = EXCLUDED.date_created,
+		"date_expires" = EXCLUDED.date_expires
+	WHERE
+		idempotency_keys.date_expires <= EXCLUDED.date_created
+	RETURNING
+		*`
+
+	// The insert returns the record, so it has to be sent to the primary.
+	var dbRec dbRecord
+	if err := database.NamedQueryStruct(database.WithPrimary(ctx), s.log, s.db, q, toDBRecord(rec), &dbRec); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return fmt.Errorf("namedquerystruct: %w", idempotency.ErrExists)
+		}
+		return fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return nil
+}
+
+// Update stores the response of a completed request. The record is only
+// updated while it still belongs to the request that claimed the key,
+// otherwise idempotency.ErrClaimLost is returned.
+func (s *Store) Update(ctx context.Context, rec idempotency.Record) error {
+	const q = `
+	UPDATE
+		idempotency_keys
+	SET
+		"status" = :status,
+		"body" = :body,
+		"date_expires" = :date_expires
+	WHERE
+		subject = :subject AND
+		idempotency_key = :idempotency_key AND
+		date_created = :date_created
+	RETURNING
+		*`
+
+	// The update returns the record, so it has to be sent to the primary.
+	var dbRec dbRecord
+	if err := database.NamedQueryStruct(database.WithPrimary(ctx), s.log, s.db, q, toDBRecord(rec), &dbRec); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return fmt.Errorf("namedquerystruct: %w", idempotency.ErrClaimLost)
+		}
+		return fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a record from the database. The record is only removed
+// while it still belongs to the request that claimed the key.
+func (s *Store) Delete(ctx context.Context, rec idempotency.Record) error {
+	data := struct {
+		Subject     string    `db:"subject"`
+		Key         string    `db:"idempotency_key"`
+		DateCreated time.Time `db:"date_created"`
+	}{
+		Subject:     rec.Subject,
+		Key:         rec.Key,
+		DateCreated: rec.DateCreated.UTC(),
+	}
+
+	const q = `
+	DELETE FROM
+		idempotency_keys
+	WHERE
+		subject = :subject AND
+		idempotency_key = :idempotency_key AND
+		date_created = :date_created`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired removes the records that expired by now.
+func (s *Store) DeleteExpired(ctx context.Context, now time.Time) error {
+	data := struct {
+		Now time.Time `db:"now"`
+	}{
+		Now: now.UTC(),
+	}
+
+	const q = `
+	DELETE FROM
+		idempotency_keys
+	WHERE
+		date_expires <= :now`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByKey gets the record for the specified subject and key.
+func (s *Store) QueryByKey(ctx context.Context, subject string, key string) (idempotency.Record, error) {
+	data := struct {
+		Subject string `db:"subject"`
+		Key     string `db:"idempotency_key"`
+	}{
+		Subject: subject,
+		Key:     key,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		idempotency_keys
+	WHERE
+		subject = :subject AND idempotency_key = :idempotency_key`
+
+	// A key used on another instance must be seen right away, so the
+	// replication lag can't be tolerated.
+	var dbRec dbRecord
+	if err := database.NamedQueryStruct(database.WithPrimary(ctx), s.log, s.db, q, data, &dbRec); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return idempotency.Record{}, fmt.Errorf("namedquerystruct: %w", idempotency.ErrNotFound)
+		}
+		return idempotency.Record{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toRecord(dbRec), nil
+}
@@ -0,0 +1,43 @@
+package idempotencydb
+
+import (
+	"time"
+
+	"github.com/ardanlabs/service/business/web/idempotency"
+)
+
+// dbRecord represent the structure we need for moving data
+// between the app and the database.
+type dbRecord struct {
+	Subject     string    `db:"subject"`
+	Key         string    `db:"idempotency_key"`
+	Fingerprint string    `db:"fingerprint"`
+	Status      int       `db:"status"`
+	Body        []byte    `db:"body"`
+	DateCreated time.Time `db:"date_created"`
+	DateExpires time.Time `db:"date_expires"`
+}
+
+func toDBRecord(rec idempotency.Record) dbRecord {
+	return dbRecord{
+		Subject:     rec.Subject,
+		Key:         rec.Key,
+		Fingerprint: rec.Fingerprint,
+		Status:      rec.Status,
+		Body:        rec.Body,
+		DateCreated: rec.DateCreated.UTC(),
+		DateExpires: rec.DateExpires.UTC(),
+	}
+}
+
+func toRecord(dbRec dbRecord) idempotency.Record {
+	return idempotency.Record{
+		Subject:     dbRec.Subject,
+		Key:         dbRec.Key,
+		Fingerprint: dbRec.Fingerprint,
+		Status:      dbRec.Status,
+		Body:        dbRec.Body,
+		DateCreated: dbRec.DateCreated.In(time.UTC),
+		DateExpires: dbRec.DateExpires.In(time.UTC),
+	}
+}
@@ -5,6 +5,11 @@ import (
 	"context"
 	"expvar"
 	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/prom"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // This holds the single instance of the metrics value needed for
@@ -17,11 +22,18 @@ var m *metrics
 
 // metrics represents the set of metrics we gather. These fields are
 // safe to be accessed concurrently thanks to expvar. No extra abstraction is required.
+// The same measurements are recorded against the OpenTelemetry instruments.
 type metrics struct {
+	otel instruments
+
 	goroutines *expvar.Int
 	requests   *expvar.Int
 	errors     *expvar.Int
 	panics     *expvar.Int
+	timeouts   *expvar.Int
+	inFlight   *expvar.Int
+	duration   *prom.HistogramVec
+	responses  *prom.CounterVec
 }
 
 // init constructs the metrics value that will be used to capture metrics.
@@ -30,10 +42,24 @@ type metrics struct {
 // sure this initialization only happens once.
 func init() {
 	m = &metrics{
+		otel:       newInstruments(),
 		goroutines: expvar.NewInt("goroutines"),
 		requests:   expvar.NewInt("requests"),
 		errors:     expvar.NewInt("errors"),
 		panics:     expvar.NewInt("panics"),
+		timeouts:   expvar.NewInt("timeouts"),
+		inFlight:   expvar.NewInt("requests_in_flight"),
+		duration: prom.NewHistogramVec(
+			"request_duration_seconds",
+			"Duration of requests by route pattern and method.",
+			prom.DefBuckets,
+			"route", "method",
+		),
+		responses: prom.NewCounterVec(
+			"responses_total",
+			"Responses by route pattern, method and status code.",
+			"route", "method", "code",
+		),
 	}
 }
 
@@ -61,6 +87,7 @@ func AddGoroutines(ctx context.Context) {
 func AddRequests(ctx context.Context) {
 	if v, ok := ctx.Value(key).(*metrics); ok {
 		v.requests.Add(1)
+		v.otel.requests.Add(ctx, 1)
 	}
 }
 
@@ -68,6 +95,7 @@ func AddRequests(ctx context.Context) {
 func AddErrors(ctx context.Context) {
 	if v, ok := ctx.Value(key).(*metrics); ok {
 		v.errors.Add(1)
+		v.otel.errors.Add(ctx, 1)
 	}
 }
 
@@ -75,5 +103,42 @@ func AddErrors(ctx context.Context) {
 func AddPanics(ctx context.Context) {
 	if v, ok := ctx.Value(key).(*metrics); ok {
 		v.panics.Add(1)
+		v.otel.panics.Add(ctx, 1)
+	}
+}
+
+// AddTimeouts increments the timeouts metric by 1.
+func AddTimeouts(ctx context.Context) {
+	if v, ok := ctx.Value(key).(*metrics); ok {
+		v.timeouts.Add(1)
+		v.otel.timeouts.Add(ctx, 1)
+	}
+}
+
+// AddInFlight adjusts the number of requests being handled by the delta.
+func AddInFlight(ctx context.Context, delta int64) {
+	if v, ok := ctx.Value(key).(*metrics); ok {
+		v.inFlight.Add(delta)
+		v.otel.inFlight.Add(ctx, delta)
+	}
+}
+
+// AddRoute records the duration and status code of a request against the
+// route pattern that handled it. The pattern is used instead of the raw path
+// to keep the number of series bounded. When the request is part of a sampled
+// trace, the trace id is kept as an exemplar of the duration.
+func AddRoute(ctx context.Context, route string, method string, statusCode int, d time.Duration) {
+	if v, ok := ctx.Value(key).(*metrics); ok {
+		var traceID string
+		if sc := trace.SpanContextFromContext(ctx); sc.IsSampled() {
+			traceID = sc.TraceID().String()
+		}
+
+		v.duration.ObserveWithExemplar(d.Seconds(), traceID, route, method)
+		v.responses.Add(1, route, method, strconv.Itoa(statusCode))
+
+		attrs := routeAttributes(route, method, statusCode)
+		v.otel.duration.Record(ctx, d.Seconds(), attrs)
+		v.otel.responses.Add(ctx, 1, attrs)
 	}
 }
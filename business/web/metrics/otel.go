@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"context"
+	"runtime"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/global"
+)
+
+// instruments mirrors the expvar metrics as OpenTelemetry instruments so they
+// can be exported through OTLP. The instruments are created from the global
+// meter provider, which delegates to the provider configured at startup.
+type instruments struct {
+	requests  metric.Int64Counter
+	errors    metric.Int64Counter
+	panics    metric.Int64Counter
+	timeouts  metric.Int64Counter
+	inFlight  metric.Int64UpDownCounter
+	duration  metric.Float64Histogram
+	responses metric.Int64Counter
+}
+
+// newInstruments constructs the set of instruments. Errors are reported to
+// the global OpenTelemetry error handler and leave a no-op instrument behind.
+func newInstruments() instruments {
+	meter := global.Meter("github.com/ardanlabs/service/business/web/metrics")
+
+	var inst instruments
+	var err error
+
+	if inst.requests, err = meter.Int64Counter("http.server.requests", metric.WithDescription("Number of requests handled.")); err != nil {
+		otel.Handle(err)
+	}
+	if inst.errors, err = meter.Int64Counter("http.server.errors", metric.WithDescription("Number of requests that returned an error.")); err != nil {
+		otel.Handle(err)
+	}
+	if inst.panics, err = meter.Int64Counter("http.server.panics", metric.WithDescription("Number of requests that panicked.")); err != nil {
+		otel.Handle(err)
+	}
+	if inst.timeouts, err = meter.Int64Counter("http.server.timeouts", metric.WithDescription("Number of requests that exceeded their deadline.")); err != nil {
+		otel.Handle(err)
+	}
+	if inst.inFlight, err = meter.Int64UpDownCounter("http.server.active_requests", metric.WithDescription("Number of requests being handled.")); err != nil {
+		otel.Handle(err)
+	}
+	if inst.duration, err = meter.Float64Histogram("http.server.duration", metric.WithDescription("Duration of requests by route pattern and method."), metric.WithUnit("s")); err != nil {
+		otel.Handle(err)
+	}
+	if inst.responses, err = meter.Int64Counter("http.server.responses", metric.WithDescription("Responses by route pattern, method and status code.")); err != nil {
+		otel.Handle(err)
+	}
+
+	goroutines, err := meter.Int64ObservableGauge("runtime.go.goroutines", metric.WithDescription("Number of goroutines that currently exist."))
+	if err != nil {
+		otel.Handle(err)
+		return inst
+	}
+
+	f := func(ctx context.Context, o metric.Observer) error {
+		o.ObserveInt64(goroutines, int64(runtime.NumGoroutine()))
+		return nil
+	}
+	if _, err := meter.RegisterCallback(f, goroutines); err != nil {
+		otel.Handle(err)
+	}
+
+	return inst
+}
+
+// routeAttributes returns the attributes identifying the route and status
+// code of a request.
+func routeAttributes(route string, method string, statusCode int) metric.MeasurementOption {
+	return metric.WithAttributes(
+		attribute.String("http.route", route),
+		attribute.String("http.method", method),
+		attribute.Int("http.status_code", statusCode),
+	)
+}
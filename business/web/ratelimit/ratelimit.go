@@ -0,0 +1,163 @@
+// Package ratelimit provides support for limiting the rate of requests using
+// a token bucket per key.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrLimitExceeded is returned when a key has no tokens left in its bucket.
+var ErrLimitExceeded = errors.New("rate limit exceeded")
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve the buckets.
+type Storer interface {
+	Take(ctx context.Context, key string, rate Rate, now time.Time) (Result, error)
+	Prune(ctx context.Context, now time.Time) error
+}
+
+// =============================================================================
+
+// Rate defines how many requests are allowed within a period. The bucket
+// holds up to Limit tokens and is refilled evenly over the period.
+type Rate struct {
+	Limit  int
+	Period time.Duration
+}
+
+// perSecond returns the number of tokens added to the bucket each second.
+func (r Rate) perSecond() float64 {
+	return float64(r.Limit) / r.Period.Seconds()
+}
+
+// Result describes the state of a bucket after taking a token from it.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Bucket represents the tokens available for a key at a point in time.
+type Bucket struct {
+	Tokens  float64
+	Updated time.Time
+}
+
+// Take refills the bucket for the time elapsed since it was last updated and
+// then attempts to take a token from it. A zero value bucket is full. The
+// updated bucket is returned to be stored by the caller.
+func (b Bucket) Take(rate Rate, now time.Time) (Bucket, Result) {
+	limit := float64(rate.Limit)
+	perSecond := rate.perSecond()
+
+	tokens := limit
+	if !b.Updated.IsZero() {
+		elapsed := now.Sub(b.Updated).Seconds()
+		if elapsed < 0 {
+			elapsed = 0
+		}
+		tokens = math.Min(limit, b.Tokens+elapsed*perSecond)
+	}
+
+	res := Result{
+		Limit: rate.Limit,
+	}
+
+	switch {
+	case tokens >= 1:
+		tokens--
+		res.Allowed = true
+
+	default:
+		res.RetryAfter = seconds((1 - tokens) / perSecond)
+	}
+
+	res.Remaining = int(tokens)
+	res.Reset = seconds((limit - tokens) / perSecond)
+
+	bucket := Bucket{
+		Tokens:  tokens,
+		Updated: now,
+	}
+
+	return bucket, res
+}
+
+// seconds converts a number of seconds into a duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// =============================================================================
+
+// Limiter manages the set of APIs for rate limiting.
+type Limiter struct {
+	storer Storer
+}
+
+// NewLimiter constructs a limiter for use.
+func NewLimiter(storer Storer) *Limiter {
+	return &Limiter{
+		storer: storer,
+	}
+}
+
+// Take takes a token from the bucket for the specified key.
+func (l *Limiter) Take(ctx context.Context, key string, rate Rate) (Result, error) {
+	if rate.Limit <= 0 || rate.Period <= 0 {
+		return Result{}, fmt.Errorf("invalid rate: limit[%d] period[%s]", rate.Limit, rate.Period)
+	}
+
+	res, err := l.storer.Take(ctx, key, rate, time.Now().UTC())
+	if err != nil {
+		return Result{}, fmt.Errorf("take: %w", err)
+	}
+
+	return res, nil
+}
+
+// StartPrune removes the buckets that have refilled at the specified interval
+// until the returned function is called. Each prune is given the interval to
+// complete.
+func (l *Limiter) StartPrune(log *zap.SugaredLogger, interval time.Duration) (stop func()) {
+	shutdown := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				l.prune(log, interval)
+			case <-shutdown:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(shutdown)
+		<-done
+	}
+}
+
+func (l *Limiter) prune(log *zap.SugaredLogger, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := l.storer.Prune(ctx, time.Now().UTC()); err != nil {
+		log.Errorw("ratelimit", "status", "prune failed", "ERROR", err)
+	}
+}
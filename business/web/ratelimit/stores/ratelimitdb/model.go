@@ -0,0 +1,32 @@
+package ratelimitdb
+
+import (
+	"time"
+
+	"github.com/ardanlabs/service/business/web/ratelimit"
+)
+
+// dbBucket represent the structure we need for moving data
+// between the app and the database.
+type dbBucket struct {
+	Key         string    `db:"limit_key"`
+	Tokens      float64   `db:"tokens"`
+	DateUpdated time.Time `db:"date_updated"`
+	DateFull    time.Time `db:"date_full"`
+}
+
+func toDBBucket(key string, b ratelimit.Bucket, full time.Time) dbBucket {
+	return dbBucket{
+		Key:         key,
+		Tokens:      b.Tokens,
+		DateUpdated: b.Updated.UTC(),
+		DateFull:    full.UTC(),
+	}
+}
+
+func toBucket(dbBkt dbBucket) ratelimit.Bucket {
+	return ratelimit.Bucket{
+		Tokens:  dbBkt.Tokens,
+		Updated: dbBkt.DateUpdated.In(time.UTC),
+	}
+}
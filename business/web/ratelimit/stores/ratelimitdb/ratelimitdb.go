@@ -0,0 +1,155 @@
+// Package ratelimitdb contains rate limiting buckets stored in the database.
+// It allows every instance of the service to share the same buckets.
+package ratelimitdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/sys/database"
+	"github.com/ardanlabs/service/business/web/ratelimit"
+	"go.uber.org/zap"
+)
+
+// maxAttempts is how many times a take is attempted when the bucket is
+// changed by a concurrent request.
+const maxAttempts = 5
+
+// errConflict is returned when the bucket changed since it was read.
+var errConflict = errors.New("bucket changed concurrently")
+
+// Store manages the set of APIs for rate limiting bucket database access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  *database.DB
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *zap.SugaredLogger, db *database.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Take takes a token from the bucket for the specified key. No lock is held
+// between reading and writing the bucket. The write only succeeds when the
+// bucket is unchanged since it was read, otherwise the take is attempted
+// again with the new bucket.
+func (s *Store) Take(ctx context.Context, key string, rate ratelimit.Rate, now time.Time) (ratelimit.Result, error) {
+	ctx = database.WithPrimary(ctx)
+
+	for attempt := 1; ; attempt++ {
+		res, err := s.take(ctx, key, rate, now)
+		if err == nil {
+			return res, nil
+		}
+
+		if !errors.Is(err, errConflict) || attempt == maxAttempts {
+			return ratelimit.Result{}, fmt.Errorf("attempt[%d]: %w", attempt, err)
+		}
+	}
+}
+
+func (s *Store) take(ctx context.Context, key string, rate ratelimit.Rate, now time.Time) (ratelimit.Result, error) {
+	data := struct {
+		Key string `db:"limit_key"`
+	}{
+		Key: key,
+	}
+
+	const qSelect = `
+	SELECT
+		limit_key, tokens, date_updated, date_full
+	FROM
+		rate_limits
+	WHERE
+		limit_key = :limit_key`
+
+	var dbBkts []dbBucket
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, qSelect, data, &dbBkts); err != nil {
+		return ratelimit.Result{}, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	// A missing bucket is full, which is what the zero value bucket is.
+	var prev ratelimit.Bucket
+	if len(dbBkts) > 0 {
+		prev = toBucket(dbBkts[0])
+	}
+
+	bucket, res := prev.Take(rate, now)
+	dbBkt := toDBBucket(key, bucket, now.Add(res.Reset))
+
+	const qInsert = `
+	INSERT INTO rate_limits
+		(limit_key, tokens, date_updated, date_full)
+	VALUES
+		(:limit_key, :tokens, :date_updated, :date_full)
+	ON CONFLICT (limit_key) DO NOTHING
+	RETURNING limit_key`
+
+	const qUpdate = `
+	UPDATE
+		rate_limits
+	SET
+		"tokens" = :tokens,
+		"date_updated" = :date_updated,
+		"date_full" = :date_full
+	WHERE
+		limit_key = :limit_key AND
+		tokens = :prev_tokens AND
+		date_updated = :prev_date_updated
+	RETURNING limit_key`
+
+	q := qInsert
+	var arg any = dbBkt
+	if len(dbBkts) > 0 {
+		q = qUpdate
+		arg = struct {
+			dbBucket
+			PrevTokens      float64   `db:"prev_tokens"`
+			PrevDateUpdated time.Time `db:"prev_date_updated"`
+		}{
+			dbBucket:        dbBkt,
+			PrevTokens:      dbBkts[0].Tokens,
+			PrevDateUpdated: dbBkts[0].DateUpdated,
+		}
+	}
+
+	var keys []struct {
+		Key string `db:"limit_key"`
+	}
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, arg, &keys); err != nil {
+		return ratelimit.Result{}, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	if len(keys) == 0 {
+		return ratelimit.Result{}, errConflict
+	}
+
+	return res, nil
+}
+
+// Prune removes the buckets that are full by now, since a full bucket is the
+// same as a missing one.
+func (s *Store) Prune(ctx context.Context, now time.Time) error {
+	data := struct {
+		Now time.Time `db:"now"`
+	}{
+		Now: now.UTC(),
+	}
+
+	const q = `
+	DELETE FROM
+		rate_limits
+	WHERE
+		date_full <= :now`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+// Package ratelimitmem contains rate limiting buckets held in memory. It is
+// suitable when a single instance of the service is running.
+package ratelimitmem
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ardanlabs/service/business/web/ratelimit"
+)
+
+// pruneInterval is how often buckets that have refilled are removed.
+const pruneInterval = time.Minute
+
+// entry is a bucket along with the time it will be full again.
+type entry struct {
+	bucket ratelimit.Bucket
+	full   time.Time
+}
+
+// Store manages the set of APIs for rate limiting buckets in memory.
+type Store struct {
+	mu      sync.Mutex
+	entries map[string]entry
+	pruned  time.Time
+}
+
+// NewStore constructs the api for in memory bucket access.
+func NewStore() *Store {
+	return &Store{
+		entries: map[string]entry{},
+	}
+}
+
+// Take takes a token from the bucket for the specified key.
+func (s *Store) Take(ctx context.Context, key string, rate ratelimit.Rate, now time.Time) (ratelimit.Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(now)
+
+	bucket, res := s.entries[key].bucket.Take(rate, now)
+	s.entries[key] = entry{
+		bucket: bucket,
+		full:   now.Add(res.Reset),
+	}
+
+	return res, nil
+}
+
+// Prune removes the buckets that have refilled by now, since a full bucket
+// is the same as a missing one.
+func (s *Store) Prune(ctx context.Context, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeFull(now)
+
+	return nil
+}
+
+// prune removes the buckets that have refilled once per prune interval, so
+// memory stays bounded without a scheduled prune.
+func (s *Store) prune(now time.Time) {
+	if now.Sub(s.pruned) < pruneInterval {
+		return
+	}
+
+	s.removeFull(now)
+}
+
+func (s *Store) removeFull(now time.Time) {
+	for key, e := range s.entries {
+		if !now.Before(e.full) {
+			delete(s.entries, key)
+		}
+	}
+
+	s.pruned = now
+}
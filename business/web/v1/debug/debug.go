@@ -2,16 +2,48 @@
 package debug
 
 import (
+	"encoding/json"
 	"expvar"
 	"net/http"
 	"net/http/pprof"
+
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/prom"
+	"github.com/ardanlabs/service/foundation/web"
 )
 
+// Options represent optional parameters.
+type Options struct {
+	app    *web.App
+	levels *logger.Levels
+}
+
+// WithRoutes exposes the routes registered with the specified application
+// on the /debug/routes endpoint.
+func WithRoutes(app *web.App) func(opts *Options) {
+	return func(opts *Options) {
+		opts.app = app
+	}
+}
+
+// WithLevels exposes the log levels on the /debug/loglevels endpoint so they
+// can be viewed and changed while the service is running.
+func WithLevels(levels *logger.Levels) func(opts *Options) {
+	return func(opts *Options) {
+		opts.levels = levels
+	}
+}
+
 // Mux registers all the debug routes from the standard library into a new mux
 // bypassing the use of the DefaultServerMux. Using the DefaultServerMux would
 // be a security risk since a dependency could inject a handler into our service
 // without us knowing it.
-func Mux() *http.ServeMux {
+func Mux(options ...func(opts *Options)) *http.ServeMux {
+	var opts Options
+	for _, option := range options {
+		option(&opts)
+	}
+
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/debug/pprof/", pprof.Index)
@@ -20,6 +52,24 @@ func Mux() *http.ServeMux {
 	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
 	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
 	mux.Handle("/debug/vars", expvar.Handler())
+	mux.Handle("/metrics", prom.Handler())
+
+	if opts.app != nil {
+		mux.HandleFunc("/debug/routes", routes(opts.app))
+	}
+
+	if opts.levels != nil {
+		mux.Handle("/debug/loglevels", opts.levels)
+	}
 
 	return mux
 }
+
+// routes returns a handler that lists the method, path and middleware of
+// every route registered with the application.
+func routes(app *web.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(app.Routes())
+	}
+}
@@ -0,0 +1,130 @@
+// Package fieldset provides support for sparse fieldsets, which let a client
+// select the attributes returned for the items of a list and the related
+// resources embedded in them.
+package fieldset
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ardanlabs/service/business/sys/validate"
+)
+
+// Parse parses the fields query parameter, a comma separated list of the
+// names of the attributes to return. Every name must be one of the specified
+// names. It returns nil when the parameter isn't set, meaning every attribute
+// is returned.
+func Parse(r *http.Request, valid map[string]string) ([]string, error) {
+	return parseList(r, "fields", valid)
+}
+
+// ParseInclude parses the include query parameter, a comma separated list of
+// the related resources to embed. Every name must be one of the specified
+// names. It returns the set of resources to embed.
+func ParseInclude(r *http.Request, valid map[string]struct{}) (map[string]bool, error) {
+	list, err := parseList(r, "include", valid)
+	if err != nil {
+		return nil, err
+	}
+
+	include := make(map[string]bool, len(list))
+	for _, name := range list {
+		include[name] = true
+	}
+
+	return include, nil
+}
+
+func parseList[T any](r *http.Request, key string, valid map[string]T) ([]string, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return nil, nil
+	}
+
+	var list []string
+	for _, name := range strings.Split(v, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		if _, exists := valid[name]; !exists {
+			names := make([]string, 0, len(valid))
+			for name := range valid {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			return nil, validate.NewFieldsError(key, fmt.Errorf("unknown name %q, valid names are: %s", name, strings.Join(names, ", ")))
+		}
+
+		list = append(list, name)
+	}
+
+	return list, nil
+}
+
+// Has reports whether the attribute is returned. Every attribute is returned
+// when no attributes were selected.
+func Has(fields []string, field string) bool {
+	if len(fields) == 0 {
+		return true
+	}
+
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Map returns the set of values the selected attributes map to, leaving out
+// the empty ones. It returns nil when no attributes were selected.
+func Map(fields []string, mapping map[string]string) []string {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var values []string
+	for _, field := range fields {
+		if v := mapping[field]; v != "" && !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+// Select returns the items with only the selected attributes, using the JSON
+// names of the items.
+func Select[T any](items []T, fields []string) ([]map[string]json.RawMessage, error) {
+	selected := make([]map[string]json.RawMessage, len(items))
+	for i, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, fmt.Errorf("marshal: %w", err)
+		}
+
+		var attrs map[string]json.RawMessage
+		if err := json.Unmarshal(data, &attrs); err != nil {
+			return nil, fmt.Errorf("unmarshal: %w", err)
+		}
+
+		m := make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			if v, exists := attrs[field]; exists {
+				m[field] = v
+			}
+		}
+		selected[i] = m
+	}
+
+	return selected, nil
+}
@@ -0,0 +1,75 @@
+package fieldset_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/business/sys/validate"
+	"github.com/ardanlabs/service/business/web/v1/fieldset"
+)
+
+// Test_Fieldset validates the selection of the attributes of a list.
+func Test_Fieldset(t *testing.T) {
+	t.Parallel()
+
+	valid := map[string]string{
+		"id":       "productid",
+		"name":     "name",
+		"userID":   "userid",
+		"userName": "userid",
+	}
+
+	r := httptest.NewRequest("GET", "/v1/products?fields=id,%20userID,userName&include=user", nil)
+
+	fields, err := fieldset.Parse(r, valid)
+	if err != nil {
+		t.Fatalf("Should be able to parse the fields: %s", err)
+	}
+
+	if len(fields) != 3 || fields[1] != "userID" {
+		t.Logf("got: %v", fields)
+		t.Fatalf("Should get the trimmed fields in order.")
+	}
+
+	if got := fieldset.Map(fields, valid); len(got) != 2 {
+		t.Logf("got: %v", got)
+		t.Fatalf("Should map the fields without duplicates.")
+	}
+
+	if !fieldset.Has(nil, "name") || fieldset.Has(fields, "name") {
+		t.Fatalf("Should report every field selected only when none are given.")
+	}
+
+	include, err := fieldset.ParseInclude(r, map[string]struct{}{"user": {}})
+	if err != nil || !include["user"] {
+		t.Logf("got: %v %v", include, err)
+		t.Fatalf("Should be able to parse the included resources.")
+	}
+
+	items := []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}{
+		{ID: "1", Name: "Comic Books"},
+	}
+
+	selected, err := fieldset.Select(items, []string{"name"})
+	if err != nil {
+		t.Fatalf("Should be able to select the fields: %s", err)
+	}
+
+	if len(selected[0]) != 1 || string(selected[0]["name"]) != `"Comic Books"` {
+		t.Logf("got: %v", selected)
+		t.Fatalf("Should only keep the selected fields.")
+	}
+
+	r = httptest.NewRequest("GET", "/v1/products?fields=id,cost", nil)
+
+	_, err = fieldset.Parse(r, valid)
+	fe := validate.GetFieldErrors(err).Fields()
+	if msg := fe["fields"]; !strings.Contains(msg, "id, name, userID, userName") {
+		t.Logf("got: %v", fe)
+		t.Fatalf("Should get an error listing the valid fields.")
+	}
+}
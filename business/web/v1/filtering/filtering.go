@@ -0,0 +1,138 @@
+// Package filtering provides support for parsing the query string of a
+// request into filter values, so every resource shares the same grammar:
+//
+//	key=value                    matches the value exactly
+//	key=value1,value2            matches any of the values
+//	min_key=value&max_key=value  matches a range of numbers, ends included
+//	start_key=date&end_key=date  matches a range of RFC3339 dates, ends included
+package filtering
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/service/business/sys/validate"
+	"github.com/google/uuid"
+)
+
+// Parser parses the query string of a request. It collects the errors of
+// every parameter that fails to parse so they can be reported at once.
+type Parser struct {
+	values url.Values
+	errs   validate.FieldErrors
+}
+
+// New constructs a parser for the query string of the request.
+func New(r *http.Request) *Parser {
+	return &Parser{
+		values: r.URL.Query(),
+	}
+}
+
+// Err returns the errors of the parameters that failed to parse as a
+// validate.FieldErrors value, or nil.
+func (p *Parser) Err() error {
+	if len(p.errs) == 0 {
+		return nil
+	}
+	return p.errs
+}
+
+func (p *Parser) addError(key string, err error) {
+	p.errs = append(p.errs, validate.FieldError{
+		Field: key,
+		Err:   err.Error(),
+	})
+}
+
+// =============================================================================
+
+// Value parses the parameter with the specified key. It returns nil when the
+// parameter isn't set or fails to parse.
+func Value[T any](p *Parser, key string, parse func(string) (T, error)) *T {
+	v := p.values.Get(key)
+	if v == "" {
+		return nil
+	}
+
+	t, err := parse(v)
+	if err != nil {
+		p.addError(key, err)
+		return nil
+	}
+
+	return &t
+}
+
+// List parses the comma separated values of the parameter with the specified
+// key. It returns nil when the parameter isn't set or a value fails to parse.
+func List[T any](p *Parser, key string, parse func(string) (T, error)) []T {
+	v := p.values.Get(key)
+	if v == "" {
+		return nil
+	}
+
+	parts := strings.Split(v, ",")
+	list := make([]T, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		t, err := parse(part)
+		if err != nil {
+			p.addError(key, err)
+			return nil
+		}
+		list = append(list, t)
+	}
+
+	return list
+}
+
+// Range parses the min_ and max_ parameters of the specified key.
+func Range[T any](p *Parser, key string, parse func(string) (T, error)) (min *T, max *T) {
+	return Value(p, "min_"+key, parse), Value(p, "max_"+key, parse)
+}
+
+// DateRange parses the start_ and end_ parameters of the specified key.
+func DateRange(p *Parser, key string) (start *time.Time, end *time.Time) {
+	return Value(p, "start_"+key, Time), Value(p, "end_"+key, Time)
+}
+
+// =============================================================================
+
+// String returns the value as is.
+func String(v string) (string, error) {
+	return v, nil
+}
+
+// Int parses the value as an integer.
+func Int(v string) (int, error) {
+	return strconv.Atoi(v)
+}
+
+// Float parses the value as a floating point number.
+func Float(v string) (float64, error) {
+	return strconv.ParseFloat(v, 64)
+}
+
+// Bool parses the value as a boolean.
+func Bool(v string) (bool, error) {
+	return strconv.ParseBool(v)
+}
+
+// UUID parses the value as a UUID.
+func UUID(v string) (uuid.UUID, error) {
+	return uuid.Parse(v)
+}
+
+// Time parses the value as an RFC3339 date, returned in UTC.
+func Time(v string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, v)
+	return t.UTC(), err
+}
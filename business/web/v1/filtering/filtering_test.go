@@ -0,0 +1,68 @@
+package filtering_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ardanlabs/service/business/sys/validate"
+	"github.com/ardanlabs/service/business/web/v1/filtering"
+)
+
+// Test_Filtering validates the query string grammar shared by the resources.
+func Test_Filtering(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest("GET", "/v1/products?name=Comic&product_id=a,b&min_cost=10&max_cost=20.5&start_updated_date=2023-01-01T10:00:00%2B02:00&enabled=true", nil)
+	p := filtering.New(r)
+
+	name := filtering.Value(p, "name", filtering.String)
+	if name == nil || *name != "Comic" {
+		t.Fatalf("Should be able to parse a value.")
+	}
+
+	if v := filtering.Value(p, "quantity", filtering.Int); v != nil {
+		t.Fatalf("Should get nil for a parameter that isn't set.")
+	}
+
+	names := filtering.List(p, "name", filtering.String)
+	if len(names) != 1 {
+		t.Logf("got: %v", names)
+		t.Fatalf("Should be able to parse a list of one value.")
+	}
+
+	min, max := filtering.Range(p, "cost", filtering.Float)
+	if min == nil || *min != 10 || max == nil || *max != 20.5 {
+		t.Fatalf("Should be able to parse a range.")
+	}
+
+	start, end := filtering.DateRange(p, "updated_date")
+	if start == nil || end != nil {
+		t.Fatalf("Should be able to parse an open date range.")
+	}
+
+	if start.Location().String() != "UTC" || start.Hour() != 8 {
+		t.Logf("got: %v", start)
+		t.Fatalf("Should convert the dates to UTC.")
+	}
+
+	enabled := filtering.Value(p, "enabled", filtering.Bool)
+	if enabled == nil || !*enabled {
+		t.Fatalf("Should be able to parse a boolean.")
+	}
+
+	if err := p.Err(); err != nil {
+		t.Fatalf("Should NOT get an error yet: %s", err)
+	}
+
+	if ids := filtering.List(p, "product_id", filtering.UUID); ids != nil {
+		t.Fatalf("Should get nil for a list with an invalid value.")
+	}
+
+	filtering.Value(p, "enabled", filtering.Int)
+
+	fields := validate.GetFieldErrors(p.Err()).Fields()
+	if _, exists := fields["product_id"]; !exists || len(fields) != 2 {
+		t.Logf("got: %v", fields)
+		t.Fatalf("Should get an error for every invalid parameter.")
+	}
+}
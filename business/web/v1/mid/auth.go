@@ -7,6 +7,7 @@ import (
 
 	"github.com/ardanlabs/service/business/web/auth"
 	v1 "github.com/ardanlabs/service/business/web/v1"
+	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/web"
 	"github.com/google/uuid"
 )
@@ -16,16 +17,28 @@ var (
 	ErrInvalidID = errors.New("ID is not in its proper form")
 )
 
-// Authenticate validates a JWT from the `Authorization` header.
+// Authenticate validates a JWT from the `Authorization` header. Internal
+// callers that present a verified client certificate over mutual TLS, and no
+// token, are authenticated by their certificate instead.
 func Authenticate(a *auth.Auth) web.Middleware {
 	m := func(handler web.Handler) web.Handler {
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-			claims, err := a.Authenticate(ctx, r.Header.Get("authorization"))
+			var claims auth.Claims
+			var err error
+
+			bearer := r.Header.Get("authorization")
+			switch {
+			case bearer == "" && r.TLS != nil && len(r.TLS.VerifiedChains) > 0:
+				claims, err = a.AuthenticateCert(r.TLS.VerifiedChains[0][0])
+			default:
+				claims, err = a.Authenticate(ctx, bearer)
+			}
 			if err != nil {
 				return auth.NewAuthError("authenticate: failed: %s", err)
 			}
 
 			ctx = auth.SetClaims(ctx, claims)
+			ctx = logger.AddFields(ctx, "user_id", claims.Subject)
 
 			return handler(ctx, w, r)
 		}
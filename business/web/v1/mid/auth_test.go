@@ -0,0 +1,70 @@
+package mid_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/ardanlabs/service/business/core/user"
+	"github.com/ardanlabs/service/business/web/auth"
+	"github.com/ardanlabs/service/business/web/v1/mid"
+	"github.com/ardanlabs/service/foundation/web"
+	"go.uber.org/zap"
+)
+
+// Test_AuthenticateCert validates callers presenting a verified client
+// certificate are authenticated with the roles of its common name.
+func Test_AuthenticateCert(t *testing.T) {
+	t.Parallel()
+
+	log := zap.NewNop().Sugar()
+
+	a, err := auth.New(auth.Config{
+		Log: log,
+		CertRoles: map[string][]user.Role{
+			"billing": {user.RoleAdmin},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create an authenticator: %s", err)
+	}
+
+	app := web.NewApp(make(chan os.Signal, 1), nil, mid.Errors(log))
+
+	var subject string
+	h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		subject = auth.GetClaims(ctx).Subject
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+	app.Handle(http.MethodGet, "v1", "/admin", h, mid.Authenticate(a), mid.Authorize(a, auth.RuleAdminOnly))
+
+	send := func(cn string) *httptest.ResponseRecorder {
+		cert := x509.Certificate{Subject: pkix.Name{CommonName: cn}}
+		r := httptest.NewRequest(http.MethodGet, "/v1/admin", nil)
+		r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{&cert}}}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		return w
+	}
+
+	w := send("billing")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Should receive a status code of 204 for a configured certificate : %d", w.Code)
+	}
+
+	if subject != "billing" {
+		t.Logf("got: %v", subject)
+		t.Logf("exp: %v", "billing")
+		t.Error("Should set the claims of the certificate")
+	}
+
+	w = send("reports")
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Should receive a status code of 401 for an unknown certificate : %d", w.Code)
+	}
+}
@@ -2,18 +2,108 @@ package mid
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ardanlabs/service/foundation/web"
 )
 
+// CORSPolicy describes the Cross-Origin Resource Sharing policy for a group
+// of routes. An origin can be "*" to allow any origin, or can use a wildcard
+// for the subdomains of a host, such as "https://*.example.com". A policy
+// without any origins disables CORS.
+type CORSPolicy struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// Enabled reports whether the policy allows any origin at all.
+func (p CORSPolicy) Enabled() bool {
+	return len(p.AllowedOrigins) > 0
+}
+
+// Validate checks the policy can be enforced. Allowing any origin with
+// credentials would let every site make credentialed calls, which is why
+// browsers refuse "*" with credentials, so that policy is rejected.
+func (p CORSPolicy) Validate() error {
+	if !p.AllowCredentials {
+		return nil
+	}
+
+	for _, allowed := range p.AllowedOrigins {
+		if allowed == "*" {
+			return errors.New("cors: any origin can't be allowed with credentials")
+		}
+	}
+
+	return nil
+}
+
+// allowOrigin returns the value of the Access-Control-Allow-Origin header
+// for the origin, or false when the origin is not allowed.
+func (p CORSPolicy) allowOrigin(origin string) (string, bool) {
+	if origin == "" {
+		return "", false
+	}
+
+	origin = strings.ToLower(origin)
+	for _, allowed := range p.AllowedOrigins {
+		allowed = strings.ToLower(allowed)
+
+		switch {
+		case allowed == "*":
+			return "*", true
+
+		case allowed == origin:
+			return origin, true
+
+		case strings.Contains(allowed, "*"):
+			prefix, suffix, _ := strings.Cut(allowed, "*")
+			sub := strings.TrimSuffix(strings.TrimPrefix(origin, prefix), suffix)
+			if len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) && !strings.ContainsAny(sub, "/:") {
+				return origin, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// =============================================================================
+
 // Cors sets the response headers needed for Cross-Origin Resource Sharing
-func Cors(origin string) web.Middleware {
+// on requests from an allowed origin. It is bound to a route group ahead of
+// authentication so error responses can be read by the client as well. The
+// policy must be valid.
+func Cors(policy CORSPolicy) web.Middleware {
+	if err := policy.Validate(); err != nil {
+		panic(err.Error())
+	}
+
 	m := func(handler web.Handler) web.Handler {
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+			if !policy.Enabled() {
+				return handler(ctx, w, r)
+			}
+
+			w.Header().Add("Vary", "Origin")
+
+			if origin, ok := policy.allowOrigin(r.Header.Get("Origin")); ok {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				if policy.AllowCredentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+				if len(policy.ExposedHeaders) > 0 {
+					w.Header().Set("Access-Control-Expose-Headers", strings.Join(policy.ExposedHeaders, ", "))
+				}
+			}
 
 			return handler(ctx, w, r)
 		}
@@ -23,3 +113,46 @@ func Cors(origin string) web.Middleware {
 
 	return m
 }
+
+// Preflight answers CORS preflight requests for a route group. It responds
+// with a 204 and, when the origin and requested method are allowed, the
+// headers describing the policy. It is bound with Group.HandleOptions so it
+// runs without the rest of the middleware.
+func Preflight(policy CORSPolicy) web.Handler {
+	h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		w.Header().Add("Vary", "Origin")
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+
+		origin, ok := policy.allowOrigin(r.Header.Get("Origin"))
+		if !ok || !contains(policy.AllowedMethods, r.Header.Get("Access-Control-Request-Method")) {
+			return web.Respond(ctx, w, nil, http.StatusNoContent)
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(policy.AllowedMethods, ", "))
+		if len(policy.AllowedHeaders) > 0 {
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(policy.AllowedHeaders, ", "))
+		}
+		if policy.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+		if policy.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+		}
+
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+
+	return h
+}
+
+// contains reports whether the value is in the list, ignoring case.
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ardanlabs/service/business/sys/database"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// ReadYourWrites sends the queries of a request to the primary database once
+// the request made a write, so the request reads back what it wrote instead
+// of a replica that may lag behind.
+func ReadYourWrites() web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return handler(database.WithStickiness(ctx), w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
@@ -6,7 +6,9 @@ import (
 
 	"github.com/ardanlabs/service/business/sys/validate"
 	"github.com/ardanlabs/service/business/web/auth"
+	"github.com/ardanlabs/service/business/web/metrics"
 	v1 "github.com/ardanlabs/service/business/web/v1"
+	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/web"
 	"go.uber.org/zap"
 )
@@ -18,7 +20,12 @@ func Errors(log *zap.SugaredLogger) web.Middleware {
 	m := func(handler web.Handler) web.Handler {
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 			if err := handler(ctx, w, r); err != nil {
-				log.Errorw("ERROR", "trace_id", web.GetTraceID(ctx), "message", err)
+				logger.WithContext(ctx, log).Errorw("ERROR", "message", err)
+
+				metrics.AddErrors(ctx)
+				if web.IsTimeout(err) {
+					metrics.AddTimeouts(ctx)
+				}
 
 				ctx, span := web.AddSpan(ctx, "business.web.v1.mid.error")
 				span.RecordError(err)
@@ -28,6 +35,12 @@ func Errors(log *zap.SugaredLogger) web.Middleware {
 				var status int
 
 				switch {
+				case web.IsTimeout(err):
+					er = v1.ErrorResponse{
+						Error: http.StatusText(http.StatusServiceUnavailable),
+					}
+					status = http.StatusServiceUnavailable
+
 				case validate.IsFieldErrors(err):
 					fieldErrors := validate.GetFieldErrors(err)
 					er = v1.ErrorResponse{
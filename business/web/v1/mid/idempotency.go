@@ -0,0 +1,127 @@
+package mid
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ardanlabs/service/business/web/auth"
+	"github.com/ardanlabs/service/business/web/idempotency"
+	v1 "github.com/ardanlabs/service/business/web/v1"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+	"go.uber.org/zap"
+)
+
+// Idempotency honors the `Idempotency-Key` header for the routes it is bound
+// to. The response of the first request made with a key is stored and
+// replayed when the request is retried. Reusing a key for a different
+// request is rejected. Requests without the header run as normal.
+func Idempotency(log *zap.SugaredLogger, core *idempotency.Core) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			key := r.Header.Get("Idempotency-Key")
+			if key == "" {
+				return handler(ctx, w, r)
+			}
+
+			if len(key) > idempotency.MaxKeyLength {
+				return v1.NewRequestError(fmt.Errorf("idempotency key must be at most %d characters", idempotency.MaxKeyLength), http.StatusBadRequest)
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				return fmt.Errorf("read body: %w", err)
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			subject := auth.GetClaims(ctx).Subject
+			fingerprint := idempotency.Fingerprint(r.Method, r.URL.Path, body)
+
+			rec, replay, err := core.Begin(ctx, subject, key, fingerprint)
+			switch {
+			case errors.Is(err, idempotency.ErrMismatch):
+				return v1.NewRequestError(err, http.StatusUnprocessableEntity)
+			case errors.Is(err, idempotency.ErrInProgress):
+				return v1.NewRequestError(err, http.StatusConflict)
+			case err != nil:
+				return fmt.Errorf("idempotency: %w", err)
+			}
+
+			if replay {
+				web.SetStatusCode(ctx, rec.Status)
+
+				w.Header().Set("Idempotent-Replayed", "true")
+				if len(rec.Body) > 0 {
+					w.Header().Set("Content-Type", "application/json")
+				}
+				w.WriteHeader(rec.Status)
+
+				if _, err := w.Write(rec.Body); err != nil {
+					return err
+				}
+				return nil
+			}
+
+			// The claim on the key is released if the handler fails or
+			// panics so the request can be retried.
+			completed := false
+			defer func() {
+				if completed {
+					return
+				}
+				if err := core.Release(ctx, rec); err != nil {
+					logger.WithContext(ctx, log).Errorw("idempotency", "key", key, "ERROR", err)
+				}
+			}()
+
+			rw := responseRecorder{ResponseWriter: w}
+			if err := handler(ctx, &rw, r); err != nil {
+				return err
+			}
+
+			if rw.status == 0 {
+				rw.status = http.StatusOK
+			}
+
+			// The response has already been sent so a failure to store it
+			// can't be reported to the client.
+			if err := core.Complete(ctx, rec, rw.status, rw.body.Bytes()); err != nil {
+				logger.WithContext(ctx, log).Errorw("idempotency", "key", key, "ERROR", err)
+				return nil
+			}
+			completed = true
+
+			return nil
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// responseRecorder captures the status and body written to the response.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader records the status before writing it to the response.
+func (rr *responseRecorder) WriteHeader(status int) {
+	rr.status = status
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the data before writing it to the response.
+func (rr *responseRecorder) Write(data []byte) (int, error) {
+	if rr.status == 0 {
+		rr.status = http.StatusOK
+	}
+	rr.body.Write(data)
+	return rr.ResponseWriter.Write(data)
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/web"
 	"go.uber.org/zap"
 )
@@ -15,18 +16,19 @@ func Logger(log *zap.SugaredLogger) web.Middleware {
 	m := func(handler web.Handler) web.Handler {
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 			v := web.GetValues(ctx)
+			clog := logger.WithContext(ctx, log)
 
 			path := r.URL.Path
 			if r.URL.RawQuery != "" {
 				path = fmt.Sprintf("%s?%s", path, r.URL.RawQuery)
 			}
 
-			log.Infow("request started", "trace_id", v.TraceID, "method", r.Method, "path", path,
+			clog.Infow("request started", "method", r.Method, "path", path,
 				"remoteaddr", r.RemoteAddr)
 
 			err := handler(ctx, w, r)
 
-			log.Infow("request completed", "trace_id", v.TraceID, "method", r.Method, "path", path,
+			clog.Infow("request completed", "method", r.Method, "path", path,
 				"remoteaddr", r.RemoteAddr, "statuscode", v.StatusCode, "since", time.Since(v.Now))
 
 			return err
@@ -3,25 +3,30 @@ package mid
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/ardanlabs/service/business/web/metrics"
 	"github.com/ardanlabs/service/foundation/web"
 )
 
-// Metrics updates program counters.
+// Metrics updates program counters. It must run outside of the Errors
+// middleware so the status code of error responses is known when the route
+// metrics are recorded.
 func Metrics() web.Middleware {
 	m := func(handler web.Handler) web.Handler {
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 			ctx = metrics.Set(ctx)
 
+			metrics.AddInFlight(ctx, 1)
+			defer metrics.AddInFlight(ctx, -1)
+
 			err := handler(ctx, w, r)
 
 			metrics.AddRequests(ctx)
 			metrics.AddGoroutines(ctx)
 
-			if err != nil {
-				metrics.AddErrors(ctx)
-			}
+			v := web.GetValues(ctx)
+			metrics.AddRoute(ctx, web.RoutePattern(r), r.Method, v.StatusCode, time.Since(v.Now))
 
 			return err
 		}
@@ -0,0 +1,41 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ardanlabs/service/business/sys/validate"
+	"github.com/ardanlabs/service/foundation/openapi"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// OpenAPI validates the path parameters, query parameters and body of the
+// request against the OpenAPI document before the handler is called. Any
+// problems are returned as field errors.
+func OpenAPI(v *openapi.Validator) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			errs, err := v.Validate(r, web.RoutePattern(r), web.Params(r))
+			if err != nil {
+				return err
+			}
+
+			if len(errs) > 0 {
+				fields := make(validate.FieldErrors, len(errs))
+				for i, fe := range errs {
+					fields[i] = validate.FieldError{
+						Field: fe.Field,
+						Err:   fe.Err,
+					}
+				}
+				return fields
+			}
+
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
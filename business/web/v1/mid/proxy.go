@@ -0,0 +1,93 @@
+package mid
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// TrustedProxies is the set of networks of the proxies, such as the ingress
+// or the load balancer, whose X-Forwarded-For header is trusted.
+type TrustedProxies []*net.IPNet
+
+// ParseTrustedProxies parses the addresses of the trusted proxies. Each
+// value is an IP address or a network in CIDR notation.
+func ParseTrustedProxies(values []string) (TrustedProxies, error) {
+	proxies := make(TrustedProxies, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid proxy address %q", value)
+			}
+
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy network %q: %w", value, err)
+		}
+		proxies = append(proxies, network)
+	}
+
+	return proxies, nil
+}
+
+// ClientIP returns the IP address of the client making the request. When
+// the request comes from a trusted proxy, the X-Forwarded-For header is read
+// from right to left and the first address that isn't a trusted proxy is
+// the client. Addresses added by the client itself are never used since
+// they come before the ones added by the proxies.
+func (tp TrustedProxies) ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if !tp.trusted(host) {
+		return host
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		host = hop
+		if !tp.trusted(hop) {
+			break
+		}
+	}
+
+	return host
+}
+
+// trusted reports whether the address belongs to a trusted proxy.
+func (tp TrustedProxies) trusted(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range tp {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
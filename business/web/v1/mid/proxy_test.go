@@ -0,0 +1,57 @@
+package mid_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ardanlabs/service/business/web/v1/mid"
+)
+
+// Test_ClientIP validates the client IP is only taken from X-Forwarded-For
+// when the request comes from a trusted proxy.
+func Test_ClientIP(t *testing.T) {
+	t.Parallel()
+
+	proxies, err := mid.ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1", "fd00::/8"})
+	if err != nil {
+		t.Fatalf("Should be able to parse the trusted proxies : %s", err)
+	}
+
+	tt := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		exp        string
+	}{
+		{"direct", "203.0.113.7:1234", nil, "203.0.113.7"},
+		{"untrustedProxy", "203.0.113.7:1234", []string{"198.51.100.1"}, "203.0.113.7"},
+		{"proxy", "10.1.2.3:1234", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"proxyAddress", "192.168.1.1:1234", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"proxyIPv6", "[fd00::1]:1234", []string{"2001:db8::1"}, "2001:db8::1"},
+		{"proxyChain", "10.1.2.3:1234", []string{"198.51.100.1, 10.9.9.9"}, "198.51.100.1"},
+		{"spoofed", "10.1.2.3:1234", []string{"1.2.3.4, 198.51.100.1"}, "198.51.100.1"},
+		{"headers", "10.1.2.3:1234", []string{"1.2.3.4", "198.51.100.1"}, "198.51.100.1"},
+		{"invalid", "10.1.2.3:1234", []string{"198.51.100.1, unknown"}, "10.1.2.3"},
+		{"noHeader", "10.1.2.3:1234", nil, "10.1.2.3"},
+		{"allProxies", "10.1.2.3:1234", []string{"10.4.4.4, 10.9.9.9"}, "10.4.4.4"},
+	}
+
+	for _, tst := range tt {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = tst.remoteAddr
+		for _, v := range tst.forwarded {
+			r.Header.Add("X-Forwarded-For", v)
+		}
+
+		if got := proxies.ClientIP(r); got != tst.exp {
+			t.Logf("got: %v", got)
+			t.Logf("exp: %v", tst.exp)
+			t.Errorf("%s: Should identify the client IP", tst.name)
+		}
+	}
+
+	if _, err := mid.ParseTrustedProxies([]string{"ingress"}); err == nil {
+		t.Error("Should NOT be able to parse an invalid proxy address")
+	}
+}
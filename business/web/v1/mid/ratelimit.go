@@ -0,0 +1,69 @@
+package mid
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ardanlabs/service/business/web/auth"
+	"github.com/ardanlabs/service/business/web/ratelimit"
+	v1 "github.com/ardanlabs/service/business/web/v1"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// RateLimit limits the rate of requests for a route group using a token
+// bucket per caller. Callers are identified by the subject of their claims,
+// falling back to the client IP address for unauthenticated requests, which
+// is taken from the X-Forwarded-For header of the trusted proxies. The name
+// keeps the buckets of different groups apart. A rate with a zero limit
+// disables rate limiting, any other rate requires a limiter.
+func RateLimit(l *ratelimit.Limiter, name string, rate ratelimit.Rate, proxies TrustedProxies) web.Middleware {
+	if rate.Limit > 0 && l == nil {
+		panic(fmt.Sprintf("ratelimit: group %q has a rate of %d but no limiter", name, rate.Limit))
+	}
+
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if rate.Limit <= 0 {
+				return handler(ctx, w, r)
+			}
+
+			res, err := l.Take(ctx, name+":"+caller(ctx, r, proxies), rate)
+			if err != nil {
+				return fmt.Errorf("ratelimit: %w", err)
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			w.Header().Set("RateLimit-Reset", ceilSeconds(res.Reset))
+
+			if !res.Allowed {
+				w.Header().Set("Retry-After", ceilSeconds(res.RetryAfter))
+				return v1.NewRequestError(ratelimit.ErrLimitExceeded, http.StatusTooManyRequests)
+			}
+
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// caller identifies the caller making the request.
+func caller(ctx context.Context, r *http.Request, proxies TrustedProxies) string {
+	if claims := auth.GetClaims(ctx); claims.Subject != "" {
+		return "sub:" + claims.Subject
+	}
+
+	return "ip:" + proxies.ClientIP(r)
+}
+
+// ceilSeconds formats the duration as a whole number of seconds, rounded up.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
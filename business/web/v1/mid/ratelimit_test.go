@@ -0,0 +1,154 @@
+package mid_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/web/ratelimit"
+	"github.com/ardanlabs/service/business/web/ratelimit/stores/ratelimitmem"
+	"github.com/ardanlabs/service/business/web/v1/mid"
+	"github.com/ardanlabs/service/foundation/web"
+	"go.uber.org/zap"
+)
+
+// Test_RateLimit validates callers are limited to the rate of their group.
+func Test_RateLimit(t *testing.T) {
+	t.Parallel()
+
+	log := zap.NewNop().Sugar()
+	app := web.NewApp(make(chan os.Signal, 1), nil, mid.Errors(log))
+
+	limiter := ratelimit.NewLimiter(ratelimitmem.NewStore())
+	rate := ratelimit.Rate{Limit: 2, Period: time.Minute}
+
+	h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+	app.Group("v1", mid.RateLimit(limiter, "test", rate, nil)).Handle(http.MethodGet, "/limited", h)
+
+	send := func(remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/v1/limited", nil)
+		r.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		return w
+	}
+
+	for i := 0; i < rate.Limit; i++ {
+		w := send("10.0.0.1:1234")
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Should receive a status code of 204 for request %d : %d", i, w.Code)
+		}
+	}
+
+	w := send("10.0.0.1:5678")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Should receive a status code of 429 once the limit is reached : %d", w.Code)
+	}
+
+	if got := w.Header().Get("RateLimit-Remaining"); got != "0" {
+		t.Logf("got: %v", got)
+		t.Logf("exp: %v", "0")
+		t.Error("Should have no requests remaining")
+	}
+
+	if got := w.Header().Get("Retry-After"); got != "30" {
+		t.Logf("got: %v", got)
+		t.Logf("exp: %v", "30")
+		t.Error("Should retry after a token has been added to the bucket")
+	}
+
+	w = send("10.0.0.2:1234")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Should receive a status code of 204 for a different caller : %d", w.Code)
+	}
+}
+
+// Test_RateLimitProxy validates anonymous callers behind a trusted proxy
+// get a bucket of their own.
+func Test_RateLimitProxy(t *testing.T) {
+	t.Parallel()
+
+	log := zap.NewNop().Sugar()
+	app := web.NewApp(make(chan os.Signal, 1), nil, mid.Errors(log))
+
+	proxies, err := mid.ParseTrustedProxies([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatalf("Should be able to parse the trusted proxies : %s", err)
+	}
+
+	limiter := ratelimit.NewLimiter(ratelimitmem.NewStore())
+	rate := ratelimit.Rate{Limit: 1, Period: time.Minute}
+
+	h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+	app.Group("v1", mid.RateLimit(limiter, "test", rate, proxies)).Handle(http.MethodGet, "/limited", h)
+
+	send := func(client string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/v1/limited", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+		r.Header.Set("X-Forwarded-For", client)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := send("203.0.113.1"); w.Code != http.StatusNoContent {
+		t.Fatalf("Should receive a status code of 204 for the first client : %d", w.Code)
+	}
+
+	if w := send("203.0.113.2"); w.Code != http.StatusNoContent {
+		t.Fatalf("Should receive a status code of 204 for a different client behind the proxy : %d", w.Code)
+	}
+
+	if w := send("203.0.113.1"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Should receive a status code of 429 once the client reached the limit : %d", w.Code)
+	}
+}
+
+// Test_RateLimitNoLimiter validates a rate can't be set without a limiter.
+func Test_RateLimitNoLimiter(t *testing.T) {
+	t.Parallel()
+
+	mid.RateLimit(nil, "test", ratelimit.Rate{}, nil)
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Should panic for a rate without a limiter")
+		}
+	}()
+
+	mid.RateLimit(nil, "test", ratelimit.Rate{Limit: 1, Period: time.Minute}, nil)
+}
+
+// Test_RateLimitPrune validates the memory store removes refilled buckets.
+func Test_RateLimitPrune(t *testing.T) {
+	t.Parallel()
+
+	store := ratelimitmem.NewStore()
+	rate := ratelimit.Rate{Limit: 2, Period: time.Minute}
+	now := time.Now()
+
+	if _, err := store.Take(context.Background(), "key", rate, now); err != nil {
+		t.Fatalf("Should be able to take a token : %s", err)
+	}
+
+	if err := store.Prune(context.Background(), now.Add(time.Minute)); err != nil {
+		t.Fatalf("Should be able to prune the buckets : %s", err)
+	}
+
+	res, err := store.Take(context.Background(), "key", ratelimit.Rate{Limit: 5, Period: time.Hour}, now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Should be able to take a token : %s", err)
+	}
+
+	if !res.Allowed || res.Remaining != 4 {
+		t.Logf("got: %+v", res)
+		t.Error("Should start from a full bucket once the old one is pruned")
+	}
+}
@@ -0,0 +1,63 @@
+package mid_test
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/web/v1/mid"
+	"github.com/ardanlabs/service/foundation/web"
+	"go.uber.org/zap"
+)
+
+// Test_SecurityHeaders validates the security headers set on every response.
+func Test_SecurityHeaders(t *testing.T) {
+	t.Parallel()
+
+	log := zap.NewNop().Sugar()
+	app := web.NewApp(make(chan os.Signal, 1), nil, mid.Errors(log), mid.SecurityHeaders(time.Hour))
+
+	h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+	app.Handle(http.MethodGet, "v1", "/secure", h)
+
+	exp := map[string]string{
+		"X-Content-Type-Options":  "nosniff",
+		"X-Frame-Options":         "DENY",
+		"Content-Security-Policy": "default-src 'none'; frame-ancestors 'none'",
+		"Referrer-Policy":         "no-referrer",
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/secure", nil)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	for name, value := range exp {
+		if got := w.Header().Get(name); got != value {
+			t.Logf("got: %v", got)
+			t.Logf("exp: %v", value)
+			t.Errorf("Should set the %s header", name)
+		}
+	}
+
+	if got := w.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Logf("got: %v", got)
+		t.Error("Should not set HSTS without TLS")
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/v1/secure", nil)
+	r.TLS = &tls.ConnectionState{}
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	if got, exp := w.Header().Get("Strict-Transport-Security"), "max-age=3600; includeSubDomains"; got != exp {
+		t.Logf("got: %v", got)
+		t.Logf("exp: %v", exp)
+		t.Error("Should set HSTS with TLS")
+	}
+}
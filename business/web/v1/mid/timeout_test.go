@@ -0,0 +1,45 @@
+package mid_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/web/v1/mid"
+	"github.com/ardanlabs/service/foundation/web"
+	"go.uber.org/zap"
+)
+
+// Test_Timeout validates a route deadline is reported as unavailable.
+func Test_Timeout(t *testing.T) {
+	t.Parallel()
+
+	log := zap.NewNop().Sugar()
+	app := web.NewApp(make(chan os.Signal, 1), nil, mid.Metrics(), mid.Errors(log))
+
+	h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	app.Handle(http.MethodGet, "v1", "/slow", h, web.Timeout(10*time.Millisecond))
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/slow", nil)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Should receive a status code of 503 for the response : %d", w.Code)
+	}
+
+	got := strings.TrimSpace(w.Body.String())
+	exp := `{"error":"Service Unavailable"}`
+	if got != exp {
+		t.Logf("got: %v", got)
+		t.Logf("exp: %v", exp)
+		t.Error("Should get the expected result")
+	}
+}
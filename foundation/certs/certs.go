@@ -0,0 +1,128 @@
+// Package certs provides support for serving TLS with a certificate that is
+// reloaded from disk when it changes.
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Names of the certificate and key files when loading from a directory. These
+// match the keys of a Kubernetes TLS secret.
+const (
+	DirCertFile = "tls.crt"
+	DirKeyFile  = "tls.key"
+)
+
+// Reloader holds a certificate and key pair loaded from disk and reloads
+// them when the files change.
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+// New constructs a Reloader for the specified certificate and key files and
+// loads them.
+func New(certFile string, keyFile string) (*Reloader, error) {
+	r := Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// NewFromDir constructs a Reloader for the certificate and key files found
+// in the specified directory.
+func NewFromDir(dir string) (*Reloader, error) {
+	return New(filepath.Join(dir, DirCertFile), filepath.Join(dir, DirKeyFile))
+}
+
+// Reload loads the certificate and key again if either file has changed
+// since they were last loaded. It reports whether a new certificate is now
+// being served. On failure the current certificate continues to be served.
+func (r *Reloader) Reload() (bool, error) {
+	modTime, err := latestModTime(r.certFile, r.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	unchanged := r.cert != nil && modTime.Equal(r.modTime)
+	r.mu.RUnlock()
+
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("loading key pair: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cert = &cert
+	r.modTime = modTime
+
+	return true, nil
+}
+
+// GetCertificate returns the current certificate. It is used as the
+// GetCertificate function of a tls.Config.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+// =============================================================================
+
+// LoadCertPool reads the PEM encoded certificates in the specified file into
+// a pool, such as the certificate authorities used to verify clients.
+func LoadCertPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.New("no certificates found")
+	}
+
+	return pool, nil
+}
+
+// latestModTime returns the most recent modification time of the files.
+func latestModTime(files ...string) (time.Time, error) {
+	var latest time.Time
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("stat: %w", err)
+		}
+
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest, nil
+}
@@ -0,0 +1,117 @@
+package certs_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/certs"
+)
+
+func Test_Reload(t *testing.T) {
+	dir := t.TempDir()
+
+	// Write the first certificate and load it from the directory.
+	writeCert(t, dir, "first", time.Now().Add(-time.Hour))
+
+	r, err := certs.NewFromDir(dir)
+	if err != nil {
+		t.Fatalf("Should be able to load the certificate : %s", err)
+	}
+
+	if cn := commonName(t, r); cn != "first" {
+		t.Errorf("Exp: %s", "first")
+		t.Errorf("Got: %s", cn)
+		t.Fatal("Should serve the first certificate")
+	}
+
+	// Nothing changed so nothing should be reloaded.
+	reloaded, err := r.Reload()
+	if err != nil {
+		t.Fatalf("Should be able to check the certificate : %s", err)
+	}
+	if reloaded {
+		t.Error("Should not reload an unchanged certificate")
+	}
+
+	// Replace the certificate and reload it.
+	writeCert(t, dir, "second", time.Now())
+
+	reloaded, err = r.Reload()
+	if err != nil {
+		t.Fatalf("Should be able to reload the certificate : %s", err)
+	}
+	if !reloaded {
+		t.Fatal("Should reload a changed certificate")
+	}
+
+	if cn := commonName(t, r); cn != "second" {
+		t.Errorf("Exp: %s", "second")
+		t.Errorf("Got: %s", cn)
+		t.Error("Should serve the second certificate")
+	}
+}
+
+// writeCert writes a self signed certificate and key to the directory with
+// the specified modification time.
+func writeCert(t *testing.T, dir string, cn string, modTime time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Should be able to generate a key : %s", err)
+	}
+
+	tmpl := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Should be able to create a certificate : %s", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Should be able to marshal the key : %s", err)
+	}
+
+	files := map[string]*pem.Block{
+		certs.DirCertFile: {Type: "CERTIFICATE", Bytes: der},
+		certs.DirKeyFile:  {Type: "EC PRIVATE KEY", Bytes: keyDER},
+	}
+
+	for name, block := range files {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, pem.EncodeToMemory(block), 0600); err != nil {
+			t.Fatalf("Should be able to write %s : %s", name, err)
+		}
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatalf("Should be able to set the time of %s : %s", name, err)
+		}
+	}
+}
+
+// commonName returns the common name of the certificate being served.
+func commonName(t *testing.T, r *certs.Reloader) string {
+	cert, err := r.GetCertificate(nil)
+	if err != nil {
+		t.Fatalf("Should be able to get the certificate : %s", err)
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("Should be able to parse the certificate : %s", err)
+	}
+
+	return leaf.Subject.CommonName
+}
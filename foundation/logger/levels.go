@@ -0,0 +1,229 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Levels manages the minimum level of the loggers by name so the verbosity of
+// a single package can be changed while the service is running. A logger's
+// name is set with Named, and nested names are joined with a dot. The level
+// for a name is looked up using the full name first and then the name with
+// its leading segments removed, so a level set for "database" also applies to
+// "product.database". Loggers without a matching name use the default level.
+type Levels struct {
+	mu    sync.RWMutex
+	def   zapcore.Level
+	names map[string]zapcore.Level
+}
+
+// NewLevels constructs a set of levels using the specified default level.
+func NewLevels(def zapcore.Level) *Levels {
+	return &Levels{
+		def:   def,
+		names: make(map[string]zapcore.Level),
+	}
+}
+
+// Default returns the level used by loggers without a level of their own.
+func (l *Levels) Default() zapcore.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.def
+}
+
+// SetDefault changes the level used by loggers without a level of their own.
+func (l *Levels) SetDefault(level zapcore.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.def = level
+}
+
+// Set changes the level for the loggers with the specified name.
+func (l *Levels) Set(name string, level zapcore.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.names[name] = level
+}
+
+// Remove clears the level for the specified name so those loggers use the
+// default level again.
+func (l *Levels) Remove(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.names, name)
+}
+
+// Level returns the level that applies to the logger with the specified name.
+func (l *Levels) Level(name string) zapcore.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for name != "" {
+		if level, exists := l.names[name]; exists {
+			return level
+		}
+
+		i := strings.Index(name, ".")
+		if i < 0 {
+			break
+		}
+		name = name[i+1:]
+	}
+
+	return l.def
+}
+
+// min returns the lowest level in use by any logger.
+func (l *Levels) min() zapcore.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	min := l.def
+	for _, level := range l.names {
+		if level < min {
+			min = level
+		}
+	}
+
+	return min
+}
+
+// =============================================================================
+
+// levelsDoc is the document used to view and change the levels over http.
+type levelsDoc struct {
+	Default string            `json:"default"`
+	Names   map[string]string `json:"names"`
+}
+
+// levelChange is the document used to change the level of a name. An empty
+// name changes the default level and an empty level removes the name.
+type levelChange struct {
+	Name  string `json:"name"`
+	Level string `json:"level"`
+}
+
+// ServeHTTP implements the http.Handler interface. A GET returns the current
+// levels and a PUT changes a single level, for example:
+//
+//	curl -X PUT localhost:4000/debug/loglevels -d '{"name":"database","level":"debug"}'
+func (l *Levels) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+
+	case http.MethodPut:
+		var lc levelChange
+		if err := json.NewDecoder(r.Body).Decode(&lc); err != nil {
+			http.Error(w, fmt.Sprintf("decoding level: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		if lc.Level == "" {
+			if lc.Name == "" {
+				http.Error(w, "a level is required for the default", http.StatusBadRequest)
+				return
+			}
+			l.Remove(lc.Name)
+			break
+		}
+
+		var level zapcore.Level
+		if err := level.UnmarshalText([]byte(lc.Level)); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		switch lc.Name {
+		case "":
+			l.SetDefault(level)
+		default:
+			l.Set(lc.Name, level)
+		}
+
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	l.mu.RLock()
+	doc := levelsDoc{
+		Default: l.def.String(),
+		Names:   make(map[string]string, len(l.names)),
+	}
+	for name, level := range l.names {
+		doc.Names[name] = level.String()
+	}
+	l.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(doc)
+}
+
+// =============================================================================
+
+// levelCore filters entries using the level for the name of their logger and
+// sends the entries of the sampled names through a sampler.
+type levelCore struct {
+	zapcore.Core
+	sampled zapcore.Core
+	levels  *Levels
+	sample  map[string]bool
+}
+
+// Enabled implements the zapcore.Core interface. It reports whether any logger
+// could write at the level, the name is checked once the entry is known.
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return level >= c.levels.min()
+}
+
+// With implements the zapcore.Core interface.
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	nc := *c
+	nc.Core = c.Core.With(fields)
+	if c.sampled != nil {
+		nc.sampled = c.sampled.With(fields)
+	}
+	return &nc
+}
+
+// Check implements the zapcore.Core interface.
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level < c.levels.Level(ent.LoggerName) {
+		return ce
+	}
+
+	if c.sampled != nil && c.isSampled(ent.LoggerName) {
+		return c.sampled.Check(ent, ce)
+	}
+
+	return c.Core.Check(ent, ce)
+}
+
+// isSampled reports whether the name, or the name with its leading segments
+// removed, is configured to be sampled.
+func (c *levelCore) isSampled(name string) bool {
+	for name != "" {
+		if c.sample[name] {
+			return true
+		}
+
+		i := strings.Index(name, ".")
+		if i < 0 {
+			break
+		}
+		name = name[i+1:]
+	}
+
+	return false
+}
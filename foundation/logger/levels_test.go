@@ -0,0 +1,36 @@
+package logger
+
+import "testing"
+
+func Test_IsSampled(t *testing.T) {
+	c := levelCore{
+		sample: map[string]bool{
+			"database":      true,
+			"product.cache": true,
+		},
+	}
+
+	tt := []struct {
+		name string
+		exp  bool
+	}{
+		{"database", true},
+		{"product.database", true},
+		{"sales.product.database", true},
+		{"product.cache", true},
+		{"sales.product.cache", true},
+		{"cache", false},
+		{"database.pool", false},
+		{"databases", false},
+		{"product", false},
+		{"", false},
+	}
+
+	for _, tst := range tt {
+		if got := c.isSampled(tst.name); got != tst.exp {
+			t.Logf("got: %v", got)
+			t.Logf("exp: %v", tst.exp)
+			t.Errorf("Should report if %q is sampled", tst.name)
+		}
+	}
+}
@@ -3,15 +3,70 @@
 package logger
 
 import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+// Options represent optional parameters.
+type Options struct {
+	outputPaths []string
+	levels      *Levels
+	sample      []string
+	first       int
+	thereafter  int
+}
+
+// WithOutputPaths writes the logs to the specified paths instead of stdout.
+func WithOutputPaths(paths ...string) func(opts *Options) {
+	return func(opts *Options) {
+		opts.outputPaths = paths
+	}
+}
+
+// WithLevels uses the specified levels to decide which entries are written,
+// so they can be changed while the logger is in use.
+func WithLevels(levels *Levels) func(opts *Options) {
+	return func(opts *Options) {
+		opts.levels = levels
+	}
+}
+
+// WithSampling samples the entries of the loggers with the specified names,
+// such as the high volume database query logs. Every second the first entries
+// with the same level and message are written and then only every thereafter
+// entry.
+func WithSampling(first int, thereafter int, names ...string) func(opts *Options) {
+	return func(opts *Options) {
+		opts.sample = names
+		opts.first = first
+		opts.thereafter = thereafter
+	}
+}
+
 // New constructs a Sugared Logger that writes to stdout and
 // provides human-readable timestamps.
-func New(service string, outputPaths ...string) (*zap.SugaredLogger, error) {
+func New(service string, options ...func(opts *Options)) (*zap.SugaredLogger, error) {
+	var opts Options
+	for _, option := range options {
+		option(&opts)
+	}
+
+	levels := opts.levels
+	if levels == nil {
+		levels = NewLevels(zapcore.InfoLevel)
+	}
+
 	config := zap.NewProductionConfig()
 
+	// The levels and sampling are applied by the level core so they can vary
+	// by logger name, which requires the underlying core to accept everything.
+	config.Level = zap.NewAtomicLevelAt(zapcore.DebugLevel)
+	config.Sampling = nil
+
 	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 	config.DisableStacktrace = true
 	config.InitialFields = map[string]any{
@@ -19,14 +74,65 @@ func New(service string, outputPaths ...string) (*zap.SugaredLogger, error) {
 	}
 
 	config.OutputPaths = []string{"stdout"}
-	if outputPaths != nil {
-		config.OutputPaths = outputPaths
+	if opts.outputPaths != nil {
+		config.OutputPaths = opts.outputPaths
 	}
 
-	log, err := config.Build(zap.WithCaller(true))
+	wrap := func(core zapcore.Core) zapcore.Core {
+		lc := levelCore{
+			Core:   core,
+			levels: levels,
+			sample: make(map[string]bool),
+		}
+
+		if len(opts.sample) > 0 {
+			lc.sampled = zapcore.NewSamplerWithOptions(core, time.Second, opts.first, opts.thereafter)
+			for _, name := range opts.sample {
+				lc.sample[name] = true
+			}
+		}
+
+		return &lc
+	}
+
+	log, err := config.Build(zap.WithCaller(true), zap.WrapCore(wrap))
 	if err != nil {
 		return nil, err
 	}
 
 	return log.Sugar(), nil
 }
+
+// =============================================================================
+
+type ctxKey int
+
+const key ctxKey = 1
+
+// AddFields returns a context carrying the key value pairs, which are added to
+// the logs written through WithContext. This is used to carry values such as
+// the authenticated user through a request.
+func AddFields(ctx context.Context, keysAndValues ...any) context.Context {
+	fields, _ := ctx.Value(key).([]any)
+
+	all := make([]any, 0, len(fields)+len(keysAndValues))
+	all = append(all, fields...)
+	all = append(all, keysAndValues...)
+
+	return context.WithValue(ctx, key, all)
+}
+
+// WithContext returns a logger that adds the trace and span ids from the span
+// stored in the context, when there is one, and the fields added with
+// AddFields, to every log line.
+func WithContext(ctx context.Context, log *zap.SugaredLogger) *zap.SugaredLogger {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		log = log.With("trace_id", sc.TraceID().String(), "span_id", sc.SpanID().String())
+	}
+
+	if fields, ok := ctx.Value(key).([]any); ok {
+		log = log.With(fields...)
+	}
+
+	return log
+}
@@ -0,0 +1,125 @@
+package logger_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/logger"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+)
+
+func Test_Levels(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "log")
+
+	levels := logger.NewLevels(zapcore.InfoLevel)
+	log, err := logger.New("TEST", logger.WithLevels(levels), logger.WithOutputPaths(file))
+	if err != nil {
+		t.Fatalf("Should be able to construct a logger : %s", err)
+	}
+
+	// Change the level of the database loggers through the handler.
+	r := httptest.NewRequest(http.MethodPut, "/debug/loglevels", strings.NewReader(`{"name":"database","level":"debug"}`))
+	w := httptest.NewRecorder()
+	levels.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should be able to change the level : %d : %s", w.Code, w.Body.String())
+	}
+
+	ctx := logger.AddFields(context.Background(), "user_id", "45b5fbd3")
+
+	log.Debugw("default debug")
+	log.Named("product").Named("database").Debugw("database debug")
+	logger.WithContext(ctx, log).Infow("context info")
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+	logger.WithContext(trace.ContextWithSpanContext(ctx, sc), log).Infow("span info")
+	log.Sync()
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Should be able to read the log : %s", err)
+	}
+
+	var msgs []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("Should be able to unmarshal the log line : %s", err)
+		}
+		msgs = append(msgs, m)
+	}
+
+	if len(msgs) != 3 {
+		t.Fatalf("Should get only the database debug and the info lines : %d", len(msgs))
+	}
+
+	if msgs[0]["msg"] != "database debug" {
+		t.Errorf("Should get the database debug line : %v", msgs[0]["msg"])
+	}
+
+	if msgs[1]["user_id"] != "45b5fbd3" {
+		t.Errorf("Should get the user id from the context : %v", msgs[1]["user_id"])
+	}
+
+	if _, exists := msgs[1]["span_id"]; exists {
+		t.Errorf("Should not get a span id without a span : %v", msgs[1]["span_id"])
+	}
+
+	if msgs[2]["trace_id"] != sc.TraceID().String() || msgs[2]["span_id"] != sc.SpanID().String() {
+		t.Logf("got: %v %v", msgs[2]["trace_id"], msgs[2]["span_id"])
+		t.Logf("exp: %v %v", sc.TraceID(), sc.SpanID())
+		t.Error("Should get the ids of the span on the line")
+	}
+}
+
+func Test_Sampling(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "log")
+
+	log, err := logger.New("TEST", logger.WithSampling(2, 0, "database"), logger.WithOutputPaths(file))
+	if err != nil {
+		t.Fatalf("Should be able to construct a logger : %s", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		log.Named("product").Named("database").Infow("query")
+		log.Named("product").Infow("create")
+	}
+	log.Sync()
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Should be able to read the log : %s", err)
+	}
+
+	counts := make(map[string]int)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("Should be able to unmarshal the log line : %s", err)
+		}
+		counts[m["msg"].(string)]++
+	}
+
+	if counts["query"] != 2 {
+		t.Logf("got: %v", counts["query"])
+		t.Logf("exp: %v", 2)
+		t.Error("Should sample the database lines")
+	}
+
+	if counts["create"] != 5 {
+		t.Logf("got: %v", counts["create"])
+		t.Logf("exp: %v", 5)
+		t.Error("Should write every line of the loggers that aren't sampled")
+	}
+}
@@ -0,0 +1,362 @@
+// Package openapi generates an OpenAPI 3 document from the routes registered
+// with a web.App and the Go types used for requests and responses.
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// Version is the version of the OpenAPI specification that is generated.
+const Version = "3.0.3"
+
+// Set of locations a parameter can be found in.
+const (
+	InPath   = "path"
+	InQuery  = "query"
+	InHeader = "header"
+)
+
+// Config represents the information used to describe the API as a whole.
+type Config struct {
+	Title   string
+	Version string
+
+	// ErrorResponse is the value returned by the API for failed requests.
+	// It is documented as the default response of every operation.
+	ErrorResponse any
+
+	// AuthMiddleware is the name of the middleware that authenticates a
+	// route. Routes using it are marked as requiring a bearer token.
+	AuthMiddleware string
+}
+
+// Endpoint documents the contract of a single route. Request and Response
+// are zero values of the types that are decoded from and encoded to the
+// body. A nil value means there is no body.
+type Endpoint struct {
+	Method   string
+	Path     string
+	Summary  string
+	Tags     []string
+	Params   []Parameter
+	Request  any
+	Status   int
+	Response any
+}
+
+// QueryParam constructs a query string parameter of the specified type and
+// format.
+func QueryParam(name string, typ string, format string) Parameter {
+	return Parameter{
+		Name: name,
+		In:   InQuery,
+		Schema: &Schema{
+			Type:   typ,
+			Format: format,
+		},
+	}
+}
+
+// HeaderParam constructs a header parameter of the specified type and format.
+func HeaderParam(name string, typ string, format string) Parameter {
+	return Parameter{
+		Name: name,
+		In:   InHeader,
+		Schema: &Schema{
+			Type:   typ,
+			Format: format,
+		},
+	}
+}
+
+// PathParam constructs a path parameter of the specified type and format.
+func PathParam(name string, typ string, format string) Parameter {
+	return Parameter{
+		Name:     name,
+		In:       InPath,
+		Required: true,
+		Schema: &Schema{
+			Type:   typ,
+			Format: format,
+		},
+	}
+}
+
+// WithDescription returns a copy of the parameter with the specified
+// description.
+func (p Parameter) WithDescription(desc string) Parameter {
+	p.Description = desc
+	return p
+}
+
+// WithMinLength returns a copy of the parameter that only accepts values of
+// at least n characters.
+func (p Parameter) WithMinLength(n int) Parameter {
+	s := *p.Schema
+	s.MinLength = &n
+	p.Schema = &s
+	return p
+}
+
+// =============================================================================
+
+// Document represents an OpenAPI document.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info provides metadata about the API.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem describes the operations available on a single path keyed by
+// the lower case HTTP method.
+type PathItem map[string]*Operation
+
+// Operation describes a single API operation on a path.
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter describes a single operation parameter.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+// RequestBody describes a single request body.
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response describes a single response from an API operation.
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType provides the schema for a media type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the reusable objects of the document.
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme defines a security scheme used by the operations.
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// =============================================================================
+
+const (
+	mediaJSON  = "application/json"
+	bearerAuth = "bearerAuth"
+)
+
+// Generate constructs the OpenAPI document for the specified routes. Every
+// route must be documented by an endpoint and every endpoint must match a
+// route, otherwise an error describing the mismatches is returned. Routes
+// for the OPTIONS method are skipped since they only serve CORS preflight
+// requests.
+func Generate(cfg Config, routes []web.Route, endpoints []Endpoint) (Document, error) {
+	byKey := make(map[string]Endpoint, len(endpoints))
+	for _, ep := range endpoints {
+		byKey[key(ep.Method, ep.Path)] = ep
+	}
+
+	doc := Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:   cfg.Title,
+			Version: cfg.Version,
+		},
+		Paths: make(map[string]PathItem),
+	}
+
+	gen := newGenerator()
+
+	var errorSchema *Schema
+	if cfg.ErrorResponse != nil {
+		errorSchema = gen.schemaOf(cfg.ErrorResponse)
+	}
+
+	var problems []string
+	var secured bool
+
+	for _, route := range routes {
+		if route.Method == http.MethodOptions {
+			continue
+		}
+
+		k := key(route.Method, route.Path)
+		ep, exists := byKey[k]
+		if !exists {
+			problems = append(problems, fmt.Sprintf("route %q is not documented", k))
+			continue
+		}
+		delete(byKey, k)
+
+		op := Operation{
+			OperationID: operationID(route.Method, route.Path),
+			Summary:     ep.Summary,
+			Tags:        ep.Tags,
+			Parameters:  parameters(route.Path, ep.Params),
+			Responses:   make(map[string]*Response),
+		}
+
+		if ep.Request != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content: map[string]*MediaType{
+					mediaJSON: {Schema: gen.schemaOf(ep.Request)},
+				},
+			}
+		}
+
+		status := ep.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		resp := Response{
+			Description: http.StatusText(status),
+		}
+		if ep.Response != nil {
+			resp.Content = map[string]*MediaType{
+				mediaJSON: {Schema: gen.schemaOf(ep.Response)},
+			}
+		}
+		op.Responses[fmt.Sprint(status)] = &resp
+
+		if errorSchema != nil {
+			op.Responses["default"] = &Response{
+				Description: "Error",
+				Content: map[string]*MediaType{
+					mediaJSON: {Schema: errorSchema},
+				},
+			}
+		}
+
+		if cfg.AuthMiddleware != "" && route.HasMiddleware(cfg.AuthMiddleware) {
+			op.Security = []map[string][]string{{bearerAuth: {}}}
+			secured = true
+		}
+
+		path := Path(route.Path)
+		item, exists := doc.Paths[path]
+		if !exists {
+			item = make(PathItem)
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = &op
+	}
+
+	for k := range byKey {
+		problems = append(problems, fmt.Sprintf("endpoint %q has no route", k))
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return Document{}, errors.New(strings.Join(problems, ", "))
+	}
+
+	doc.Components.Schemas = gen.components
+	if secured {
+		doc.Components.SecuritySchemes = map[string]*SecurityScheme{
+			bearerAuth: {
+				Type:         "http",
+				Scheme:       "bearer",
+				BearerFormat: "JWT",
+			},
+		}
+	}
+
+	return doc, nil
+}
+
+// Path converts a route path using the ":name" and "*" parameter syntax into
+// the "{name}" syntax used by OpenAPI.
+func Path(routePath string) string {
+	segs := strings.Split(routePath, "/")
+	for i, seg := range segs {
+		switch {
+		case strings.HasPrefix(seg, ":"):
+			segs[i] = "{" + seg[1:] + "}"
+		case strings.HasPrefix(seg, "*"):
+			segs[i] = "{" + strings.TrimPrefix(seg, "*") + "}"
+		}
+	}
+
+	return strings.Join(segs, "/")
+}
+
+// =============================================================================
+
+// key returns the lookup key for a method and path pair.
+func key(method string, path string) string {
+	return method + " " + path
+}
+
+// operationID constructs a unique id for the operation such as
+// "get_v1_users_user_id".
+func operationID(method string, path string) string {
+	f := func(r rune) bool {
+		return r == '/' || r == ':' || r == '*'
+	}
+
+	parts := append([]string{strings.ToLower(method)}, strings.FieldsFunc(path, f)...)
+	return strings.Join(parts, "_")
+}
+
+// parameters returns the documented parameters along with any path parameters
+// in the route that were not documented explicitly.
+func parameters(routePath string, params []Parameter) []Parameter {
+	documented := make(map[string]bool)
+	for _, p := range params {
+		if p.In == InPath {
+			documented[p.Name] = true
+		}
+	}
+
+	var all []Parameter
+	for _, seg := range strings.Split(routePath, "/") {
+		if !strings.HasPrefix(seg, ":") {
+			continue
+		}
+
+		name := seg[1:]
+		if !documented[name] {
+			all = append(all, PathParam(name, "string", ""))
+		}
+	}
+
+	return append(all, params...)
+}
@@ -0,0 +1,305 @@
+package openapi
+
+import (
+	"encoding"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema represents the definition of a data type.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Default              any                `json:"default,omitempty"`
+}
+
+// =============================================================================
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// generator constructs schemas from Go types, collecting named struct types
+// as reusable components.
+type generator struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newGenerator() *generator {
+	return &generator{
+		components: make(map[string]*Schema),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+// schemaOf returns the schema for the type of the specified value.
+func (g *generator) schemaOf(v any) *Schema {
+	return g.schema(reflect.TypeOf(v))
+}
+
+func (g *generator) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		s := Schema{Type: "string"}
+		if t.PkgPath() == "github.com/google/uuid" && t.Name() == "UUID" {
+			s.Format = "uuid"
+		}
+		return &s
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+
+	case reflect.String:
+		return &Schema{Type: "string"}
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		return g.ref(t)
+	}
+
+	return &Schema{}
+}
+
+// ref registers the named struct type as a component and returns a schema
+// that references it.
+func (g *generator) ref(t reflect.Type) *Schema {
+	name, exists := g.names[t]
+	if !exists {
+		name = g.componentName(t)
+		g.names[t] = name
+
+		// Register the name before the properties are generated so
+		// recursive types reference the component.
+		g.components[name] = &Schema{}
+		*g.components[name] = *g.object(t)
+	}
+
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// componentName returns a unique component name for the type. Generic type
+// arguments are reduced to their type names so paging.Response[AppUser]
+// becomes Response_AppUser. If the short name is already taken by another
+// type, the package name is used as a qualifier.
+func (g *generator) componentName(t reflect.Type) string {
+	name := t.Name()
+	if idx := strings.Index(name, "["); idx >= 0 {
+		args := strings.Split(strings.TrimSuffix(name[idx+1:], "]"), ",")
+		for i, arg := range args {
+			if dot := strings.LastIndex(arg, "."); dot >= 0 {
+				arg = arg[dot+1:]
+			}
+			args[i] = arg
+		}
+		name = name[:idx] + "_" + strings.Join(args, "_")
+	}
+
+	if _, taken := g.components[name]; taken {
+		pkg := t.PkgPath()
+		if idx := strings.LastIndex(pkg, "/"); idx >= 0 {
+			pkg = pkg[idx+1:]
+		}
+		name = pkg + "." + name
+	}
+
+	return name
+}
+
+// object constructs an object schema from the exported fields of the struct
+// type, using the json tags for property names and the validate tags for
+// constraints.
+func (g *generator) object(t reflect.Type) *Schema {
+	s := Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		fld := t.Field(i)
+		if !fld.IsExported() {
+			continue
+		}
+
+		name := strings.Split(fld.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		// Embedded structs without a json name have their fields promoted.
+		if fld.Anonymous && name == "" && fld.Type.Kind() == reflect.Struct {
+			embedded := g.object(fld.Type)
+			for n, p := range embedded.Properties {
+				s.Properties[n] = p
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+
+		if name == "" {
+			name = fld.Name
+		}
+
+		tag := fld.Tag.Get("validate")
+
+		prop := g.schema(fld.Type)
+		if applyValidate(prop, tag) {
+			s.Required = append(s.Required, name)
+		}
+
+		// A missing value is decoded as the zero value of the field and the
+		// validate rules are applied to it, so document that as the default.
+		if tag != "" && !strings.Contains(tag, "required") && !strings.Contains(tag, "omitempty") && isScalar(fld.Type) {
+			prop.Default = reflect.Zero(fld.Type).Interface()
+		}
+
+		s.Properties[name] = prop
+	}
+
+	return &s
+}
+
+// isScalar reports whether the type is a boolean, number or string.
+func isScalar(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+// applyValidate maps the validate tag rules that can be described by a
+// schema onto the schema. It reports whether the field is required.
+func applyValidate(s *Schema, tag string) bool {
+	if tag == "" || s.Ref != "" {
+		return strings.Contains(tag, "required")
+	}
+
+	var required bool
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+
+		switch name {
+		case "required":
+			required = true
+
+		case "email":
+			s.Format = "email"
+
+		case "uuid", "uuid4":
+			s.Format = "uuid"
+
+		case "oneof":
+			s.Enum = strings.Fields(param)
+
+		case "gte", "gt":
+			if f, err := strconv.ParseFloat(param, 64); err == nil {
+				s.Minimum = &f
+				s.ExclusiveMinimum = name == "gt"
+			}
+
+		case "lte", "lt":
+			if f, err := strconv.ParseFloat(param, 64); err == nil {
+				s.Maximum = &f
+				s.ExclusiveMaximum = name == "lt"
+			}
+
+		case "min", "max":
+			applyLength(s, name, param)
+		}
+	}
+
+	return required
+}
+
+// applyLength applies the min and max rules which depend on the type of the
+// field they are declared on.
+func applyLength(s *Schema, rule string, param string) {
+	switch s.Type {
+	case "string":
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return
+		}
+		if rule == "min" {
+			s.MinLength = &n
+			return
+		}
+		s.MaxLength = &n
+
+	case "array":
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return
+		}
+		if rule == "min" {
+			s.MinItems = &n
+			return
+		}
+		s.MaxItems = &n
+
+	case "integer", "number":
+		f, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return
+		}
+		if rule == "min" {
+			s.Minimum = &f
+			return
+		}
+		s.Maximum = &f
+	}
+}
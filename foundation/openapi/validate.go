@@ -0,0 +1,455 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// FieldError describes a request value that does not match the document.
+type FieldError struct {
+	Field string
+	Err   string
+}
+
+// Validator validates requests against the operations in a document. The
+// document is generated on first use since it is derived from the routes,
+// which are not known until every route has been registered.
+type Validator struct {
+	generate func() (Document, error)
+	once     sync.Once
+	doc      Document
+	err      error
+}
+
+// NewValidator constructs a validator for the document returned by the
+// generate function.
+func NewValidator(generate func() (Document, error)) *Validator {
+	return &Validator{
+		generate: generate,
+	}
+}
+
+// Validate checks the path parameters, query parameters and body of the
+// request against the operation documented for the route pattern. The body
+// is restored so it can be decoded again by the handler. Requests for routes
+// that are not documented are not validated.
+func (v *Validator) Validate(r *http.Request, route string, pathParams map[string]string) ([]FieldError, error) {
+	v.once.Do(func() {
+		v.doc, v.err = v.generate()
+	})
+	if v.err != nil {
+		return nil, fmt.Errorf("generate: %w", v.err)
+	}
+
+	op, exists := v.doc.Paths[Path(route)][strings.ToLower(r.Method)]
+	if !exists {
+		return nil, nil
+	}
+
+	val := validation{
+		schemas: v.doc.Components.Schemas,
+	}
+
+	query := r.URL.Query()
+	for _, param := range op.Parameters {
+		var raw string
+		switch param.In {
+		case InPath:
+			raw = pathParams[param.Name]
+		case InQuery:
+			raw = query.Get(param.Name)
+		case InHeader:
+			raw = r.Header.Get(param.Name)
+		}
+
+		val.param(param, raw)
+	}
+
+	if op.RequestBody != nil {
+		if err := val.body(r, op.RequestBody); err != nil {
+			return nil, err
+		}
+	}
+
+	return val.errs, nil
+}
+
+// =============================================================================
+
+// validation collects the field errors for a single request.
+type validation struct {
+	schemas map[string]*Schema
+	errs    []FieldError
+}
+
+func (val *validation) fail(field string, format string, a ...any) {
+	val.errs = append(val.errs, FieldError{
+		Field: field,
+		Err:   field + " " + fmt.Sprintf(format, a...),
+	})
+}
+
+// failWith records an error produced by a parser as is, which is how the
+// handlers report the same problem.
+func (val *validation) failWith(field string, err error) {
+	val.errs = append(val.errs, FieldError{
+		Field: field,
+		Err:   err.Error(),
+	})
+}
+
+// param validates a path or query parameter provided as a string.
+func (val *validation) param(param Parameter, raw string) {
+	if raw == "" {
+		if param.Required {
+			val.fail(param.Name, "is a required field")
+		}
+		return
+	}
+
+	s := val.resolve(param.Schema)
+	if s == nil {
+		return
+	}
+
+	switch s.Type {
+	case "integer":
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			val.fail(param.Name, "must be an integer")
+			return
+		}
+		val.value(param.Name, json.Number(strconv.FormatInt(n, 10)), s)
+
+	case "number":
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			val.fail(param.Name, "must be a number")
+			return
+		}
+		val.value(param.Name, json.Number(raw), s)
+
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			val.fail(param.Name, "must be a boolean")
+			return
+		}
+		val.value(param.Name, b, s)
+
+	default:
+		val.value(param.Name, raw, s)
+	}
+}
+
+// body reads and validates the JSON document in the request body.
+func (val *validation) body(r *http.Request, rb *RequestBody) error {
+	mt, exists := rb.Content[mediaJSON]
+	if !exists || r.Body == nil {
+		return nil
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("read body: %w", err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(data))
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		if rb.Required {
+			val.fail("body", "is a required field")
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		val.fail("body", "must be a valid JSON document: %s", err)
+		return nil
+	}
+
+	val.value("", doc, mt.Schema)
+
+	return nil
+}
+
+// value validates a decoded JSON value against the schema.
+func (val *validation) value(field string, v any, s *Schema) {
+	s = val.resolve(s)
+	if s == nil || v == nil {
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			val.fail(field, "must be an object")
+			return
+		}
+		val.object(field, obj, s)
+
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			val.fail(field, "must be an array")
+			return
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			val.fail(field, "must contain at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(arr) > *s.MaxItems {
+			val.fail(field, "must contain at maximum %d items", *s.MaxItems)
+		}
+		for i, item := range arr {
+			val.value(fmt.Sprintf("%s[%d]", field, i), item, s.Items)
+		}
+
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			val.fail(field, "must be a string")
+			return
+		}
+		val.str(field, str, s)
+
+	case "integer", "number":
+		f, ok := toFloat(v)
+		if !ok {
+			val.fail(field, "must be a number")
+			return
+		}
+		if s.Type == "integer" && f != float64(int64(f)) {
+			val.fail(field, "must be an integer")
+			return
+		}
+		val.number(field, f, s)
+
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			val.fail(field, "must be a boolean")
+		}
+	}
+}
+
+// object validates the properties of an object. Required properties follow
+// the rules of the validate package, so a property holding its zero value
+// is considered missing. Missing properties with a default value have the
+// default validated since that is the value the handler will see. Keys are
+// matched to properties the way encoding/json does, preferring an exact
+// match and otherwise ignoring case.
+func (val *validation) object(field string, obj map[string]any, s *Schema) {
+	required := make(map[string]bool, len(s.Required))
+	for _, name := range s.Required {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	keys := make([]string, 0, len(obj))
+	for name := range obj {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+
+	matched := make(map[string]bool, len(obj))
+
+	for _, name := range names {
+		prop := s.Properties[name]
+		path := join(field, name)
+
+		key, exists := matchKey(keys, name)
+		if exists {
+			matched[key] = true
+		}
+
+		v := obj[key]
+		switch {
+		case required[name] && isZero(v):
+			val.fail(path, "is a required field")
+
+		case exists:
+			val.value(path, v, prop)
+
+		case prop.Default != nil:
+			val.value(path, prop.Default, prop)
+		}
+	}
+
+	for _, name := range keys {
+		if matched[name] {
+			continue
+		}
+
+		switch {
+		case s.AdditionalProperties != nil:
+			val.value(join(field, name), obj[name], s.AdditionalProperties)
+
+		case len(s.Properties) > 0:
+			val.fail(join(field, name), "is not a known field")
+		}
+	}
+}
+
+// matchKey finds the key of an object that decodes into the named property.
+// An exact match wins over keys that only differ by case.
+func matchKey(keys []string, name string) (string, bool) {
+	for _, key := range keys {
+		if key == name {
+			return key, true
+		}
+	}
+
+	for _, key := range keys {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+
+	return "", false
+}
+
+// str validates a string value against the format, length and enum rules.
+func (val *validation) str(field string, str string, s *Schema) {
+	switch s.Format {
+	case "uuid":
+		if _, err := uuid.Parse(str); err != nil {
+			val.failWith(field, err)
+			return
+		}
+
+	case "email":
+		if _, err := mail.ParseAddress(str); err != nil {
+			val.failWith(field, err)
+			return
+		}
+
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, str); err != nil {
+			val.failWith(field, err)
+			return
+		}
+	}
+
+	n := utf8.RuneCountInString(str)
+	if s.MinLength != nil && n < *s.MinLength {
+		val.fail(field, "must be at least %d characters in length", *s.MinLength)
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		val.fail(field, "must be a maximum of %d characters in length", *s.MaxLength)
+	}
+
+	if len(s.Enum) > 0 {
+		for _, e := range s.Enum {
+			if e == str {
+				return
+			}
+		}
+		val.fail(field, "must be one of [%s]", strings.Join(s.Enum, " "))
+	}
+}
+
+// number validates a numeric value against the minimum and maximum rules.
+func (val *validation) number(field string, f float64, s *Schema) {
+	if s.Minimum != nil {
+		switch {
+		case s.ExclusiveMinimum && f <= *s.Minimum:
+			val.fail(field, "must be greater than %s", formatFloat(*s.Minimum))
+		case f < *s.Minimum:
+			val.fail(field, "must be %s or greater", formatFloat(*s.Minimum))
+		}
+	}
+
+	if s.Maximum != nil {
+		switch {
+		case s.ExclusiveMaximum && f >= *s.Maximum:
+			val.fail(field, "must be less than %s", formatFloat(*s.Maximum))
+		case f > *s.Maximum:
+			val.fail(field, "must be %s or less", formatFloat(*s.Maximum))
+		}
+	}
+}
+
+// resolve follows a component reference to the schema it refers to.
+func (val *validation) resolve(s *Schema) *Schema {
+	if s == nil || s.Ref == "" {
+		return s
+	}
+
+	return val.schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+}
+
+// =============================================================================
+
+func join(parent string, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// toFloat converts a decoded JSON number, or a Go number used as a default
+// value, into a float64.
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	}
+
+	return 0, false
+}
+
+// isZero reports whether the value is missing or the zero value for its
+// type, which is how the validate package treats required fields.
+func isZero(v any) bool {
+	switch x := v.(type) {
+	case nil:
+		return true
+	case string:
+		return x == ""
+	case bool:
+		return !x
+	case json.Number:
+		f, err := x.Float64()
+		return err == nil && f == 0
+	}
+
+	return false
+}
@@ -0,0 +1,118 @@
+package prom
+
+import (
+	"bytes"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// skipVars are the variables published by the expvar package itself that
+// are replaced by the runtime statistics or carry no numeric values.
+var skipVars = map[string]bool{
+	"cmdline":  true,
+	"memstats": true,
+}
+
+// Handler returns a handler that writes the Go runtime statistics and the
+// variables published with expvar in the Prometheus text exposition format,
+// or the OpenMetrics format with exemplars when the scraper accepts it.
+// The collectors from this package are written with their labels. Other
+// variables are converted from their JSON form, where numbers and booleans
+// become untyped metrics and objects are flattened by appending their keys
+// to the metric name. Strings and arrays are skipped.
+func Handler() http.Handler {
+	f := func(w http.ResponseWriter, r *http.Request) {
+		format := FormatText
+		contentType := "text/plain; version=0.0.4; charset=utf-8"
+		if strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text") {
+			format = FormatOpenMetrics
+			contentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+		}
+
+		var buf bytes.Buffer
+
+		writeRuntime(&buf, format)
+
+		expvar.Do(func(kv expvar.KeyValue) {
+			if skipVars[kv.Key] {
+				return
+			}
+
+			name := MetricName(kv.Key)
+
+			if c, ok := kv.Value.(Collector); ok {
+				c.WriteText(&buf, name, format)
+				return
+			}
+
+			d := json.NewDecoder(strings.NewReader(kv.Value.String()))
+			d.UseNumber()
+
+			var v any
+			if err := d.Decode(&v); err != nil {
+				return
+			}
+			writeValue(&buf, name, v, format)
+		})
+
+		if format == FormatOpenMetrics {
+			buf.WriteString("# EOF\n")
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Write(buf.Bytes())
+	}
+
+	return http.HandlerFunc(f)
+}
+
+// writeValue writes a value decoded from the JSON form of an expvar variable.
+func writeValue(w io.Writer, name string, v any, format Format) {
+	switch v := v.(type) {
+	case json.Number:
+		writeHeader(w, name, "", "untyped", format)
+		fmt.Fprintf(w, "%s %s\n", name, v)
+
+	case bool:
+		var n int
+		if v {
+			n = 1
+		}
+		writeHeader(w, name, "", "untyped", format)
+		fmt.Fprintf(w, "%s %d\n", name, n)
+
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			writeValue(w, name+"_"+MetricName(key), v[key], format)
+		}
+	}
+}
+
+// MetricName converts the name of an expvar variable into a valid metric
+// name by replacing the characters that are not allowed with underscores.
+func MetricName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == ':':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9' && i > 0:
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+
+	return b.String()
+}
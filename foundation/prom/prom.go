@@ -0,0 +1,379 @@
+// Package prom provides metric types with labels that are published through
+// expvar and can be written in the Prometheus text exposition format.
+package prom
+
+import (
+	"bytes"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefBuckets are the default histogram buckets, in seconds, suitable for
+// measuring request and query latencies.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Format identifies the exposition format used to write metrics.
+type Format int
+
+// Set of supported exposition formats.
+const (
+	// FormatText is the Prometheus text format version 0.0.4.
+	FormatText Format = iota
+
+	// FormatOpenMetrics is the OpenMetrics text format version 1.0.0, which
+	// is required to carry exemplars.
+	FormatOpenMetrics
+)
+
+// Collector is implemented by the metric types in this package so they can
+// be written in the exposition formats.
+type Collector interface {
+	expvar.Var
+	WriteText(w io.Writer, name string, format Format) error
+}
+
+// =============================================================================
+
+// CounterVec is a set of counters partitioned by label values.
+type CounterVec struct {
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]*counter
+}
+
+type counter struct {
+	labelValues []string
+	value       int64
+}
+
+// NewCounterVec constructs a counter vector and publishes it with expvar
+// under the specified name. If a counter vector is already published under
+// the name, it is returned instead.
+func NewCounterVec(name string, help string, labels ...string) *CounterVec {
+	if cv, ok := expvar.Get(name).(*CounterVec); ok {
+		return cv
+	}
+
+	cv := CounterVec{
+		help:   help,
+		labels: labels,
+		values: make(map[string]*counter),
+	}
+	expvar.Publish(name, &cv)
+
+	return &cv
+}
+
+// Add adds the delta to the counter for the label values, which must be
+// provided in the order of the labels.
+func (cv *CounterVec) Add(delta int64, labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+
+	c, exists := cv.values[key]
+	if !exists {
+		c = &counter{labelValues: labelValues}
+		cv.values[key] = c
+	}
+	c.value += delta
+}
+
+// String implements the expvar.Var interface, rendering the counters as a
+// JSON object keyed by the label pairs.
+func (cv *CounterVec) String() string {
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+
+	m := make(map[string]int64, len(cv.values))
+	for _, c := range cv.values {
+		m[labelKey(cv.labels, c.labelValues)] = c.value
+	}
+
+	return marshal(m)
+}
+
+// WriteText writes the counters in the specified exposition format.
+func (cv *CounterVec) WriteText(w io.Writer, name string, format Format) error {
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+
+	var buf bytes.Buffer
+	writeHeader(&buf, name, cv.help, "counter", format)
+
+	name = counterName(name, format)
+	for _, key := range sortedKeys(cv.values) {
+		c := cv.values[key]
+		fmt.Fprintf(&buf, "%s%s %d\n", name, labelText(cv.labels, c.labelValues, "", ""), c.value)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// =============================================================================
+
+// HistogramVec is a set of histograms partitioned by label values.
+type HistogramVec struct {
+	help    string
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	values map[string]*histogram
+}
+
+type histogram struct {
+	labelValues []string
+	counts      []uint64
+	count       uint64
+	sum         float64
+	exemplars   []*exemplar
+}
+
+// exemplar is an observation recorded with the trace it was part of.
+type exemplar struct {
+	traceID string
+	value   float64
+	time    time.Time
+}
+
+// NewHistogramVec constructs a histogram vector with the specified upper
+// bounds for its buckets and publishes it with expvar under the specified
+// name. If a histogram vector is already published under the name, it is
+// returned instead.
+func NewHistogramVec(name string, help string, buckets []float64, labels ...string) *HistogramVec {
+	if hv, ok := expvar.Get(name).(*HistogramVec); ok {
+		return hv
+	}
+
+	b := append([]float64{}, buckets...)
+	sort.Float64s(b)
+
+	hv := HistogramVec{
+		help:    help,
+		labels:  labels,
+		buckets: b,
+		values:  make(map[string]*histogram),
+	}
+	expvar.Publish(name, &hv)
+
+	return &hv
+}
+
+// Observe records the value in the histogram for the label values, which
+// must be provided in the order of the labels.
+func (hv *HistogramVec) Observe(value float64, labelValues ...string) {
+	hv.ObserveWithExemplar(value, "", labelValues...)
+}
+
+// ObserveWithExemplar records the value like Observe and keeps it as the
+// exemplar of the bucket it falls into, linking the bucket to the trace. An
+// empty trace id records the value without an exemplar.
+func (hv *HistogramVec) ObserveWithExemplar(value float64, traceID string, labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+
+	hv.mu.Lock()
+	defer hv.mu.Unlock()
+
+	h, exists := hv.values[key]
+	if !exists {
+		h = &histogram{
+			labelValues: labelValues,
+			counts:      make([]uint64, len(hv.buckets)),
+			exemplars:   make([]*exemplar, len(hv.buckets)+1),
+		}
+		hv.values[key] = h
+	}
+
+	// The exemplar belongs to the smallest bucket holding the value, with
+	// the last slot used for the +Inf bucket.
+	slot := len(hv.buckets)
+	for i := len(hv.buckets) - 1; i >= 0; i-- {
+		if value > hv.buckets[i] {
+			break
+		}
+		h.counts[i]++
+		slot = i
+	}
+	h.count++
+	h.sum += value
+
+	if traceID != "" {
+		h.exemplars[slot] = &exemplar{
+			traceID: traceID,
+			value:   value,
+			time:    time.Now(),
+		}
+	}
+}
+
+// String implements the expvar.Var interface, rendering the histograms as a
+// JSON object keyed by the label pairs.
+func (hv *HistogramVec) String() string {
+	hv.mu.Lock()
+	defer hv.mu.Unlock()
+
+	type doc struct {
+		Count   uint64            `json:"count"`
+		Sum     float64           `json:"sum"`
+		Buckets map[string]uint64 `json:"buckets"`
+	}
+
+	m := make(map[string]doc, len(hv.values))
+	for _, h := range hv.values {
+		d := doc{
+			Count:   h.count,
+			Sum:     h.sum,
+			Buckets: make(map[string]uint64, len(hv.buckets)),
+		}
+		for i, upper := range hv.buckets {
+			d.Buckets[formatFloat(upper)] = h.counts[i]
+		}
+		m[labelKey(hv.labels, h.labelValues)] = d
+	}
+
+	return marshal(m)
+}
+
+// WriteText writes the histograms in the specified exposition format. The
+// exemplars are only written in the OpenMetrics format.
+func (hv *HistogramVec) WriteText(w io.Writer, name string, format Format) error {
+	hv.mu.Lock()
+	defer hv.mu.Unlock()
+
+	var buf bytes.Buffer
+	writeHeader(&buf, name, hv.help, "histogram", format)
+
+	for _, key := range sortedKeys(hv.values) {
+		h := hv.values[key]
+		for i, upper := range hv.buckets {
+			fmt.Fprintf(&buf, "%s_bucket%s %d%s\n", name, labelText(hv.labels, h.labelValues, "le", formatFloat(upper)), h.counts[i], exemplarText(h.exemplars[i], format))
+		}
+		fmt.Fprintf(&buf, "%s_bucket%s %d%s\n", name, labelText(hv.labels, h.labelValues, "le", "+Inf"), h.count, exemplarText(h.exemplars[len(hv.buckets)], format))
+		fmt.Fprintf(&buf, "%s_sum%s %s\n", name, labelText(hv.labels, h.labelValues, "", ""), formatFloat(h.sum))
+		fmt.Fprintf(&buf, "%s_count%s %d\n", name, labelText(hv.labels, h.labelValues, "", ""), h.count)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// =============================================================================
+
+// writeHeader writes the HELP and TYPE lines of a metric. OpenMetrics names
+// counter families without the _total suffix and calls untyped metrics
+// unknown.
+func writeHeader(w io.Writer, name string, help string, typ string, format Format) {
+	if format == FormatOpenMetrics {
+		switch typ {
+		case "counter":
+			name = strings.TrimSuffix(name, "_total")
+		case "untyped":
+			typ = "unknown"
+		}
+	}
+
+	if help != "" {
+		fmt.Fprintf(w, "# HELP %s %s\n", name, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help))
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, typ)
+}
+
+// counterName returns the name of a counter sample, which must end in _total
+// in the OpenMetrics format.
+func counterName(name string, format Format) string {
+	if format == FormatOpenMetrics && !strings.HasSuffix(name, "_total") {
+		return name + "_total"
+	}
+	return name
+}
+
+// exemplarText renders the exemplar of a bucket in the OpenMetrics format.
+func exemplarText(e *exemplar, format Format) string {
+	if e == nil || format != FormatOpenMetrics {
+		return ""
+	}
+
+	ts := float64(e.time.UnixNano()) / float64(time.Second)
+	return fmt.Sprintf(` # {trace_id="%s"} %s %s`, escapeLabel(e.traceID), formatFloat(e.value), strconv.FormatFloat(ts, 'f', 3, 64))
+}
+
+// labelText renders the label pairs of a series, with an optional extra
+// pair such as the upper bound of a histogram bucket.
+func labelText(labels []string, values []string, extraLabel string, extraValue string) string {
+	var pairs []string
+	for i, label := range labels {
+		var value string
+		if i < len(values) {
+			value = values[i]
+		}
+		pairs = append(pairs, label+`="`+escapeLabel(value)+`"`)
+	}
+	if extraLabel != "" {
+		pairs = append(pairs, extraLabel+`="`+extraValue+`"`)
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// labelKey renders the label pairs used as the key of the JSON documents.
+func labelKey(labels []string, values []string) string {
+	pairs := make([]string, len(labels))
+	for i, label := range labels {
+		var value string
+		if i < len(values) {
+			value = values[i]
+		}
+		pairs[i] = label + "=" + value
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(value)
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func marshal(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
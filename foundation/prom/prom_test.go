@@ -0,0 +1,118 @@
+package prom_test
+
+import (
+	"bytes"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/prom"
+)
+
+func Test_HistogramVec(t *testing.T) {
+	hv := prom.NewHistogramVec("test_duration_seconds", "Test durations.", []float64{.1, 1}, "route")
+	hv.Observe(.05, "/v1/users")
+	hv.Observe(.5, "/v1/users")
+	hv.Observe(5, "/v1/users")
+
+	if prom.NewHistogramVec("test_duration_seconds", "", nil) != hv {
+		t.Error("Should get the published histogram for the same name")
+	}
+
+	var buf bytes.Buffer
+	if err := hv.WriteText(&buf, "test_duration_seconds", prom.FormatText); err != nil {
+		t.Fatalf("Should be able to write the histogram : %s", err)
+	}
+
+	got := buf.String()
+	exp := `# HELP test_duration_seconds Test durations.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{route="/v1/users",le="0.1"} 1
+test_duration_seconds_bucket{route="/v1/users",le="1"} 2
+test_duration_seconds_bucket{route="/v1/users",le="+Inf"} 3
+test_duration_seconds_sum{route="/v1/users"} 5.55
+test_duration_seconds_count{route="/v1/users"} 3
+`
+	if got != exp {
+		t.Logf("got: %v", got)
+		t.Logf("exp: %v", exp)
+		t.Error("Should get the expected result")
+	}
+}
+
+func Test_Handler(t *testing.T) {
+	cv := prom.NewCounterVec("test_responses_total", "Test responses.", "method", "code")
+	cv.Add(1, "GET", "200")
+	cv.Add(2, "GET", "200")
+
+	w := httptest.NewRecorder()
+	prom.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("Should get a text content type : %s", w.Header().Get("Content-Type"))
+	}
+
+	exp := `test_responses_total{method="GET",code="200"} 3`
+	if !strings.Contains(w.Body.String(), exp) {
+		t.Logf("got: %v", w.Body.String())
+		t.Logf("exp: %v", exp)
+		t.Error("Should get the counter in the output")
+	}
+}
+
+func Test_HandlerExpvar(t *testing.T) {
+	expvar.NewInt("test.requests").Add(7)
+
+	m := expvar.NewMap("test_db")
+	m.Add("open", 2)
+	m.Set("name", new(expvar.String))
+
+	w := httptest.NewRecorder()
+	prom.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := w.Body.String()
+	for _, exp := range []string{
+		"test_requests 7",
+		"test_db_open 2",
+		"go_goroutines ",
+		"go_memstats_heap_alloc_bytes ",
+		`go_gc_duration_seconds{quantile="0.5"} `,
+	} {
+		if !strings.Contains(body, exp) {
+			t.Errorf("Should get %q in the output", exp)
+		}
+	}
+
+	for _, exp := range []string{"test_db_name", "memstats_", "cmdline"} {
+		if strings.Contains(body, "\n"+exp) || strings.HasPrefix(body, exp) {
+			t.Errorf("Should not get %q in the output", exp)
+		}
+	}
+}
+
+func Test_Exemplars(t *testing.T) {
+	hv := prom.NewHistogramVec("test_exemplar_seconds", "", []float64{.1, 1}, "route")
+	hv.ObserveWithExemplar(.5, "4bf92f3577b34da6a3ce929d0e0e4736", "/v1/users")
+
+	var buf bytes.Buffer
+	hv.WriteText(&buf, "test_exemplar_seconds", prom.FormatText)
+	if strings.Contains(buf.String(), "trace_id") {
+		t.Error("Should not get exemplars in the text format")
+	}
+
+	buf.Reset()
+	hv.WriteText(&buf, "test_exemplar_seconds", prom.FormatOpenMetrics)
+
+	exp := `test_exemplar_seconds_bucket{route="/v1/users",le="1"} 1 # {trace_id="4bf92f3577b34da6a3ce929d0e0e4736"} 0.5 `
+	if !strings.Contains(buf.String(), exp) {
+		t.Logf("got: %v", buf.String())
+		t.Logf("exp: %v", exp)
+		t.Error("Should get the exemplar on the bucket holding the value")
+	}
+
+	if strings.Contains(buf.String(), `le="0.1"} 0 #`) {
+		t.Error("Should not get the exemplar on a smaller bucket")
+	}
+}
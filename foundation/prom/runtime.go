@@ -0,0 +1,65 @@
+package prom
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// writeRuntime writes the Go runtime statistics covering goroutines, memory
+// and garbage collection pauses.
+func writeRuntime(w io.Writer, format Format) {
+	writeHeader(w, "go_info", "Information about the Go environment.", "gauge", format)
+	fmt.Fprintf(w, "go_info{version=%q} 1\n", runtime.Version())
+
+	writeHeader(w, "go_goroutines", "Number of goroutines that currently exist.", "gauge", format)
+	fmt.Fprintf(w, "go_goroutines %d\n", runtime.NumGoroutine())
+
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	memstats := []struct {
+		name  string
+		help  string
+		typ   string
+		value float64
+	}{
+		{"alloc_bytes", "Number of bytes allocated and still in use.", "gauge", float64(ms.Alloc)},
+		{"alloc_bytes_total", "Total number of bytes allocated even if freed.", "counter", float64(ms.TotalAlloc)},
+		{"sys_bytes", "Number of bytes obtained from the system.", "gauge", float64(ms.Sys)},
+		{"lookups_total", "Total number of pointer lookups.", "counter", float64(ms.Lookups)},
+		{"mallocs_total", "Total number of mallocs.", "counter", float64(ms.Mallocs)},
+		{"frees_total", "Total number of frees.", "counter", float64(ms.Frees)},
+		{"heap_alloc_bytes", "Number of heap bytes allocated and still in use.", "gauge", float64(ms.HeapAlloc)},
+		{"heap_sys_bytes", "Number of heap bytes obtained from the system.", "gauge", float64(ms.HeapSys)},
+		{"heap_idle_bytes", "Number of heap bytes waiting to be used.", "gauge", float64(ms.HeapIdle)},
+		{"heap_inuse_bytes", "Number of heap bytes that are in use.", "gauge", float64(ms.HeapInuse)},
+		{"heap_released_bytes", "Number of heap bytes released to the OS.", "gauge", float64(ms.HeapReleased)},
+		{"heap_objects", "Number of allocated objects.", "gauge", float64(ms.HeapObjects)},
+		{"stack_inuse_bytes", "Number of bytes in use by the stack allocator.", "gauge", float64(ms.StackInuse)},
+		{"stack_sys_bytes", "Number of bytes obtained from the system for the stack allocator.", "gauge", float64(ms.StackSys)},
+		{"gc_sys_bytes", "Number of bytes used for garbage collection system metadata.", "gauge", float64(ms.GCSys)},
+		{"next_gc_bytes", "Number of heap bytes when the next garbage collection will take place.", "gauge", float64(ms.NextGC)},
+		{"last_gc_time_seconds", "Number of seconds since 1970 of the last garbage collection.", "gauge", float64(ms.LastGC) / float64(time.Second)},
+	}
+
+	for _, m := range memstats {
+		name := "go_memstats_" + m.name
+		writeHeader(w, name, m.help, m.typ, format)
+		fmt.Fprintf(w, "%s %s\n", name, formatFloat(m.value))
+	}
+
+	// The pause quantiles are requested as the minimum, the quartiles and
+	// the maximum of the recent garbage collection pauses.
+	stats := debug.GCStats{PauseQuantiles: make([]time.Duration, 5)}
+	debug.ReadGCStats(&stats)
+
+	writeHeader(w, "go_gc_duration_seconds", "A summary of the pause duration of garbage collection cycles.", "summary", format)
+	for i, q := range []string{"0", "0.25", "0.5", "0.75", "1"} {
+		fmt.Fprintf(w, "go_gc_duration_seconds{quantile=%q} %s\n", q, formatFloat(stats.PauseQuantiles[i].Seconds()))
+	}
+	fmt.Fprintf(w, "go_gc_duration_seconds_sum %s\n", formatFloat(stats.PauseTotal.Seconds()))
+	fmt.Fprintf(w, "go_gc_duration_seconds_count %d\n", stats.NumGC)
+}
@@ -0,0 +1,254 @@
+package web
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// minCompressSize is the smallest response body worth compressing. Smaller
+// bodies can grow once the encoding overhead is added.
+const minCompressSize = 1024
+
+// Encoding describes a content coding used to compress response bodies.
+// NewWriter returns a writer that compresses the data written to it into w
+// and is closed once the response is complete.
+type Encoding struct {
+	Name      string
+	NewWriter func(w io.Writer) io.WriteCloser
+}
+
+// Gzip returns the gzip content coding using the specified compression
+// level. Writers are pooled between responses.
+func Gzip(level int) Encoding {
+	pool := sync.Pool{
+		New: func() any {
+			gw, err := gzip.NewWriterLevel(io.Discard, level)
+			if err != nil {
+				gw = gzip.NewWriter(io.Discard)
+			}
+			return gw
+		},
+	}
+
+	newWriter := func(w io.Writer) io.WriteCloser {
+		gw := pool.Get().(*gzip.Writer)
+		gw.Reset(w)
+
+		return &pooledWriter{
+			WriteCloser: gw,
+			release: func() {
+				pool.Put(gw)
+			},
+		}
+	}
+
+	return Encoding{
+		Name:      "gzip",
+		NewWriter: newWriter,
+	}
+}
+
+// zstdWindowSize bounds the window of zstd responses to the 8MB clients are
+// required to support for the zstd content coding.
+const zstdWindowSize = 8 << 20
+
+// Zstd returns the zstd content coding using the specified compression
+// level. Encoders are pooled between responses.
+func Zstd(level zstd.EncoderLevel) Encoding {
+	pool := sync.Pool{
+		New: func() any {
+			zw, err := zstd.NewWriter(nil,
+				zstd.WithEncoderLevel(level),
+				zstd.WithEncoderConcurrency(1),
+				zstd.WithWindowSize(zstdWindowSize),
+			)
+			if err != nil {
+				zw, _ = zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1), zstd.WithWindowSize(zstdWindowSize))
+			}
+			return zw
+		},
+	}
+
+	newWriter := func(w io.Writer) io.WriteCloser {
+		zw := pool.Get().(*zstd.Encoder)
+		zw.Reset(w)
+
+		return &pooledWriter{
+			WriteCloser: zw,
+			release: func() {
+				pool.Put(zw)
+			},
+		}
+	}
+
+	return Encoding{
+		Name:      "zstd",
+		NewWriter: newWriter,
+	}
+}
+
+// pooledWriter returns the compressing writer to its pool once closed.
+type pooledWriter struct {
+	io.WriteCloser
+	release func()
+}
+
+// Close flushes the compressed data and releases the writer.
+func (pw *pooledWriter) Close() error {
+	err := pw.WriteCloser.Close()
+	pw.release()
+	return err
+}
+
+// Compress compresses response bodies using the first of the specified
+// encodings the client accepts, based on the Accept-Encoding header and its
+// quality values. Encodings are listed in order of preference, which breaks
+// ties between encodings the client accepts equally. Bodies that are small
+// or already encoded are sent as is.
+func Compress(encodings ...Encoding) Middleware {
+	m := func(handler Handler) Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			enc, ok := negotiate(r.Header.Get("Accept-Encoding"), encodings)
+			if !ok || r.Method == http.MethodHead {
+				return handler(ctx, w, r)
+			}
+
+			cw := compressWriter{
+				ResponseWriter: w,
+				encoding:       enc,
+			}
+			defer cw.Close()
+
+			return handler(ctx, &cw, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// negotiate selects the encoding to use for the Accept-Encoding header.
+func negotiate(accept string, encodings []Encoding) (Encoding, bool) {
+	if accept == "" {
+		return Encoding{}, false
+	}
+
+	quality := make(map[string]float64)
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+
+		q := 1.0
+		if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+			var err error
+			if q, err = strconv.ParseFloat(strings.TrimPrefix(params, "q="), 64); err != nil {
+				continue
+			}
+		}
+
+		quality[strings.ToLower(strings.TrimSpace(name))] = q
+	}
+
+	var best Encoding
+	var bestQ float64
+	for _, enc := range encodings {
+		q, exists := quality[enc.Name]
+		if !exists {
+			q, exists = quality["*"]
+		}
+		if exists && q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+
+	return best, bestQ > 0
+}
+
+// =============================================================================
+
+// compressWriter decides whether to compress the response when the body is
+// first written, once the headers and the size of the first write are known.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding Encoding
+	status   int
+	decided  bool
+	writer   io.WriteCloser
+}
+
+// WriteHeader defers writing the status until the body is written.
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.status != 0 {
+		return
+	}
+	cw.status = status
+
+	// Responses without a body are written immediately.
+	if status == http.StatusNoContent || status == http.StatusNotModified || status < http.StatusOK {
+		cw.decided = true
+		cw.ResponseWriter.WriteHeader(status)
+	}
+}
+
+// Write compresses the data when the response qualifies for compression.
+func (cw *compressWriter) Write(data []byte) (int, error) {
+	if !cw.decided {
+		cw.decide(len(data))
+	}
+
+	if cw.writer != nil {
+		return cw.writer.Write(data)
+	}
+
+	return cw.ResponseWriter.Write(data)
+}
+
+// decide sets the headers for the chosen encoding and writes the status.
+func (cw *compressWriter) decide(size int) {
+	cw.decided = true
+
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+
+	hdr := cw.ResponseWriter.Header()
+	if size >= minCompressSize && hdr.Get("Content-Encoding") == "" {
+		hdr.Set("Content-Encoding", cw.encoding.Name)
+		hdr.Del("Content-Length")
+		cw.writer = cw.encoding.NewWriter(cw.ResponseWriter)
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.status)
+}
+
+// Close completes the compressed body, or writes the status when the
+// handler never wrote a body.
+func (cw *compressWriter) Close() error {
+	if !cw.decided {
+		cw.decided = true
+		if cw.status != 0 {
+			cw.ResponseWriter.WriteHeader(cw.status)
+		}
+		return nil
+	}
+
+	if cw.writer != nil {
+		return cw.writer.Close()
+	}
+
+	return nil
+}
+
+// Unwrap returns the underlying response writer.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
@@ -0,0 +1,133 @@
+package web_test
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Test_Compress validates responses are compressed when the client accepts
+// it and conditional requests are answered with a 304.
+func Test_Compress(t *testing.T) {
+	t.Parallel()
+
+	updated := time.Date(2023, time.March, 1, 10, 30, 0, 0, time.UTC)
+	items := []string{strings.Repeat("gopher", 500)}
+
+	app := web.NewApp(make(chan os.Signal, 1), nil, web.Compress(web.Zstd(zstd.SpeedDefault), web.Gzip(gzip.DefaultCompression)))
+
+	h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return web.RespondConditional(ctx, w, r, items, updated)
+	}
+	app.Handle(http.MethodGet, "v1", "/items", h)
+
+	send := func(headers map[string]string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/v1/items", nil)
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		return w
+	}
+
+	// -------------------------------------------------------------------------
+
+	w := send(map[string]string{"Accept-Encoding": "br;q=1.0, gzip;q=0.8"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should receive a status code of 200 for the response : %d", w.Code)
+	}
+
+	if got := w.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Logf("got: %v", got)
+		t.Logf("exp: %v", "gzip")
+		t.Fatal("Should compress the response with gzip")
+	}
+
+	gr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("Should be able to read the compressed response : %s", err)
+	}
+
+	var got []string
+	if err := json.NewDecoder(gr).Decode(&got); err != nil {
+		t.Fatalf("Should be able to unmarshal the response : %s", err)
+	}
+
+	if len(got) != 1 || got[0] != items[0] {
+		t.Error("Should get back the items")
+	}
+
+	// -------------------------------------------------------------------------
+
+	zw := send(map[string]string{"Accept-Encoding": "gzip, zstd"})
+	if zw.Code != http.StatusOK {
+		t.Fatalf("Should receive a status code of 200 for the response : %d", zw.Code)
+	}
+
+	if got := zw.Header().Get("Content-Encoding"); got != "zstd" {
+		t.Logf("got: %v", got)
+		t.Logf("exp: %v", "zstd")
+		t.Fatal("Should compress the response with zstd when preferred")
+	}
+
+	zr, err := zstd.NewReader(zw.Body)
+	if err != nil {
+		t.Fatalf("Should be able to read the compressed response : %s", err)
+	}
+	defer zr.Close()
+
+	got = nil
+	if err := json.NewDecoder(zr).Decode(&got); err != nil {
+		t.Fatalf("Should be able to unmarshal the response : %s", err)
+	}
+
+	if len(got) != 1 || got[0] != items[0] {
+		t.Error("Should get back the items")
+	}
+
+	// -------------------------------------------------------------------------
+
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Should get back an ETag")
+	}
+
+	if got := w.Header().Get("Last-Modified"); got != updated.Format(http.TimeFormat) {
+		t.Logf("got: %v", got)
+		t.Logf("exp: %v", updated.Format(http.TimeFormat))
+		t.Error("Should get back the last modified date")
+	}
+
+	// -------------------------------------------------------------------------
+
+	w = send(map[string]string{"If-None-Match": etag})
+	if w.Code != http.StatusNotModified {
+		t.Errorf("Should receive a status code of 304 for a matching ETag : %d", w.Code)
+	}
+
+	w = send(map[string]string{"If-Modified-Since": updated.Format(http.TimeFormat)})
+	if w.Code != http.StatusNotModified {
+		t.Errorf("Should receive a status code of 304 when not modified since : %d", w.Code)
+	}
+
+	w = send(map[string]string{"If-None-Match": `W/"stale"`, "If-Modified-Since": updated.Format(http.TimeFormat)})
+	if w.Code != http.StatusOK {
+		t.Errorf("Should receive a status code of 200 for a stale ETag : %d", w.Code)
+	}
+
+	if got := w.Header().Get("Content-Encoding"); got != "" {
+		t.Logf("got: %v", got)
+		t.Logf("exp: %v", "")
+		t.Error("Should not compress the response when it isn't accepted")
+	}
+}
@@ -0,0 +1,87 @@
+package web
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// RespondConditional converts a Go value to JSON and sends it to the client
+// with ETag and Last-Modified headers, so the client can revalidate its copy
+// on later requests. When the If-None-Match or If-Modified-Since header shows
+// the client already has this representation, a 304 is sent without a body.
+// A zero lastModified leaves out the Last-Modified header.
+func RespondConditional(ctx context.Context, w http.ResponseWriter, r *http.Request, data any, lastModified time.Time) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(jsonData)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	statusCode := http.StatusOK
+	if notModified(r, etag, lastModified) {
+		statusCode = http.StatusNotModified
+	}
+
+	ctx, span := AddSpan(ctx, "foundation.web.response", attribute.Int("status", statusCode))
+	defer span.End()
+
+	SetStatusCode(ctx, statusCode)
+
+	if statusCode == http.StatusNotModified {
+		w.WriteHeader(statusCode)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if _, err := w.Write(jsonData); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// notModified evaluates the conditional headers of the request. As required
+// by RFC 9110, If-Modified-Since is ignored when If-None-Match is present.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if lastModified.IsZero() {
+		return false
+	}
+
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	return !lastModified.Truncate(time.Second).After(ims)
+}
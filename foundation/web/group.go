@@ -0,0 +1,55 @@
+package web
+
+import "strings"
+
+// Group represents a set of routes that share a path prefix and a stack of
+// middleware. Groups can be nested, in which case the prefix and middleware
+// of the parent group apply first.
+type Group struct {
+	app     *App
+	prefix  string
+	mw      []Middleware
+	options Handler
+}
+
+// Group constructs a nested route group under this group's prefix. The
+// middleware provided runs after the middleware of this group.
+func (g *Group) Group(prefix string, mw ...Middleware) *Group {
+	return &Group{
+		app:     g.app,
+		prefix:  joinPrefix(g.prefix, prefix),
+		mw:      append(append([]Middleware{}, g.mw...), mw...),
+		options: g.options,
+	}
+}
+
+// HandleOptions sets a handler for OPTIONS requests to the paths of the
+// routes bound to the group from this point on, such as for answering CORS
+// preflight requests. The handler runs without any application or group
+// middleware. When groups share a path, the first group to bind it wins.
+func (g *Group) HandleOptions(handler Handler) {
+	g.options = handler
+}
+
+// Handle sets a handler function for a given HTTP method and path pair
+// under the group's prefix. The route middleware runs after the group
+// middleware.
+func (g *Group) Handle(method string, path string, handler Handler, mw ...Middleware) {
+	routeMW := append(append([]Middleware{}, g.mw...), mw...)
+	g.app.handle(method, g.prefix+path, handler, routeMW)
+
+	if g.options != nil {
+		g.app.handleOptions(g.prefix+path, g.options)
+	}
+}
+
+// joinPrefix appends the prefix to the parent prefix, making sure the result
+// has a leading slash and no trailing slash.
+func joinPrefix(parent string, prefix string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return parent
+	}
+
+	return parent + "/" + prefix
+}
@@ -17,6 +17,17 @@ func Param(r *http.Request, key string) string {
 	return m[key]
 }
 
+// Params returns all the path parameters from the request.
+func Params(r *http.Request) map[string]string {
+	return httptreemux.ContextParams(r.Context())
+}
+
+// RoutePattern returns the route pattern that matched the request, such as
+// "/v1/users/:user_id".
+func RoutePattern(r *http.Request) string {
+	return httptreemux.ContextRoute(r.Context())
+}
+
 // Decode reads the body of an HTTP request looking for a JSON document. The
 // body is decoded into the provided value.
 // If the provided value is a struct then it is checked for validation tags.
@@ -0,0 +1,85 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// pathKey stores the request path in the context before the root span of the
+// request is started so the sampler can see it.
+const pathKey ctxKey = 2
+
+// setPath stores the request path in the context.
+func setPath(ctx context.Context, path string) context.Context {
+	return context.WithValue(ctx, pathKey, path)
+}
+
+// routeSampler applies sampling ratios by request path.
+type routeSampler struct {
+	fallback sdktrace.Sampler
+	exact    map[string]sdktrace.Sampler
+	prefixes []prefixSampler
+}
+
+type prefixSampler struct {
+	prefix  string
+	sampler sdktrace.Sampler
+}
+
+// RouteSampler returns a sampler that applies the sampling ratio configured
+// for the path of the request being handled by the App, such as a ratio of 0
+// to never sample "/v1/liveness". A path ending in "*" matches every path
+// with that prefix and the longest prefix wins. Spans for other paths, or
+// started outside of a request, use the fallback sampler. The ratios take
+// precedence over the sampling decision of a parent span.
+func RouteSampler(fallback sdktrace.Sampler, ratios map[string]float64) sdktrace.Sampler {
+	rs := routeSampler{
+		fallback: fallback,
+		exact:    make(map[string]sdktrace.Sampler),
+	}
+
+	for path, ratio := range ratios {
+		sampler := sdktrace.TraceIDRatioBased(ratio)
+
+		if strings.HasSuffix(path, "*") {
+			rs.prefixes = append(rs.prefixes, prefixSampler{prefix: strings.TrimSuffix(path, "*"), sampler: sampler})
+			continue
+		}
+		rs.exact[path] = sampler
+	}
+
+	sort.Slice(rs.prefixes, func(i, j int) bool {
+		return len(rs.prefixes[i].prefix) > len(rs.prefixes[j].prefix)
+	})
+
+	return rs
+}
+
+// ShouldSample implements the sdktrace.Sampler interface.
+func (rs routeSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	path, ok := p.ParentContext.Value(pathKey).(string)
+	if !ok {
+		return rs.fallback.ShouldSample(p)
+	}
+
+	if sampler, exists := rs.exact[path]; exists {
+		return sampler.ShouldSample(p)
+	}
+
+	for _, ps := range rs.prefixes {
+		if strings.HasPrefix(path, ps.prefix) {
+			return ps.sampler.ShouldSample(p)
+		}
+	}
+
+	return rs.fallback.ShouldSample(p)
+}
+
+// Description implements the sdktrace.Sampler interface.
+func (rs routeSampler) Description() string {
+	return fmt.Sprintf("RouteSampler{%s,routes:%d}", rs.fallback.Description(), len(rs.exact)+len(rs.prefixes))
+}
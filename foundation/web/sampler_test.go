@@ -0,0 +1,55 @@
+package web_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/web"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Test_RouteSampler validates the sampling ratio of a route overrides the
+// default sampler.
+func Test_RouteSampler(t *testing.T) {
+	t.Parallel()
+
+	sampler := web.RouteSampler(
+		sdktrace.ParentBased(sdktrace.AlwaysSample()),
+		map[string]float64{"/v1/liveness": 0, "/v1/private/*": 0},
+	)
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sampler))
+	defer tp.Shutdown(context.Background())
+
+	app := web.NewApp(make(chan os.Signal, 1), tp.Tracer("test"))
+
+	sampled := make(map[string]bool)
+	h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		sampled[r.URL.Path] = trace.SpanContextFromContext(ctx).IsSampled()
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+	app.Handle(http.MethodGet, "v1", "/liveness", h)
+	app.Handle(http.MethodGet, "v1", "/users", h)
+	app.Handle(http.MethodGet, "v1", "/private/data", h)
+
+	tt := map[string]bool{
+		"/v1/liveness":     false,
+		"/v1/users":        true,
+		"/v1/private/data": false,
+	}
+
+	for path, exp := range tt {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		if got := sampled[path]; got != exp {
+			t.Logf("got: %v", got)
+			t.Logf("exp: %v", exp)
+			t.Errorf("Should get the expected sampling decision for %s", path)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// timeoutError is used to indicate the handler did not complete before the
+// deadline set for the route.
+type timeoutError struct {
+	Timeout time.Duration
+	Err     error
+}
+
+// Error is the implementation of the error interface.
+func (te *timeoutError) Error() string {
+	return fmt.Sprintf("request timed out after %s: %s", te.Timeout, te.Err)
+}
+
+// Unwrap returns the error returned by the handler.
+func (te *timeoutError) Unwrap() error {
+	return te.Err
+}
+
+// IsTimeout checks to see if the timeout error is contained in the
+// specified error value.
+func IsTimeout(err error) bool {
+	var te *timeoutError
+	return errors.As(err, &te)
+}
+
+// Timeout sets a deadline on the context passed to the handler of a route.
+// It is provided as route middleware when binding a handler that can run
+// longer than the server write timeout allows. When the handler fails after
+// the deadline has passed, the error is replaced by a timeout error and the
+// timeout is recorded on the span. A duration of zero disables the deadline.
+func Timeout(d time.Duration) Middleware {
+	m := func(handler Handler) Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if d <= 0 {
+				return handler(ctx, w, r)
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+
+			err := handler(ctx, w, r)
+			if err == nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return err
+			}
+
+			span := trace.SpanFromContext(ctx)
+			span.SetAttributes(
+				attribute.Bool("timeout", true),
+				attribute.String("timeout.duration", d.String()),
+			)
+			span.SetStatus(codes.Error, http.StatusText(http.StatusServiceUnavailable))
+
+			return &timeoutError{
+				Timeout: d,
+				Err:     err,
+			}
+		}
+
+		return h
+	}
+
+	return m
+}
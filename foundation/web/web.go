@@ -6,6 +6,11 @@ import (
 	"errors"
 	"net/http"
 	"os"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -27,6 +32,9 @@ type App struct {
 	shutdown chan os.Signal
 	mw       []Middleware
 	tracer   trace.Tracer
+	mu       sync.RWMutex
+	routes   []Route
+	options  map[string]bool
 }
 
 // NewApp creates an App value that handle a set of routes for the application.
@@ -47,6 +55,7 @@ func NewApp(shutdown chan os.Signal, tracer trace.Tracer, mw ...Middleware) *App
 		shutdown: shutdown,
 		mw:       mw,
 		tracer:   tracer,
+		options:  make(map[string]bool),
 	}
 }
 
@@ -61,15 +70,85 @@ func (a *App) SignalShutdown() {
 // tracing. The opentelemetry mux then calls the application mux to handle
 // application traffic. This was set up on line 44 in the NewApp function.
 func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	// The path is stored before the root span is started so a RouteSampler
+	// can make its decision based on it.
+	r = r.WithContext(setPath(r.Context(), r.URL.Path))
+
 	a.otmux.ServeHTTP(w, r)
 }
 
 // Handle sets a handler function for a given HTTP method and path pair
 // to the application server mux.
 func (a *App) Handle(method string, group string, path string, handler Handler, mw ...Middleware) {
+	finalPath := path
+	if group != "" {
+		finalPath = "/" + group + path
+	}
+
+	a.handle(method, finalPath, handler, mw)
+}
+
+// Group constructs a route group where every route is registered under the
+// specified path prefix and runs the specified middleware after the
+// application middleware.
+func (a *App) Group(prefix string, mw ...Middleware) *Group {
+	return &Group{
+		app:    a,
+		prefix: joinPrefix("", prefix),
+		mw:     mw,
+	}
+}
+
+// Routes returns the set of routes registered with the application, sorted
+// by path and method.
+func (a *App) Routes() []Route {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	routes := make([]Route, len(a.routes))
+	copy(routes, a.routes)
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path == routes[j].Path {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Path < routes[j].Path
+	})
+
+	return routes
+}
+
+// handle binds the handler, wrapped by the route and application middleware,
+// to the mux and records the route for introspection.
+func (a *App) handle(method string, path string, handler Handler, mw []Middleware) {
+	a.addRoute(method, path, append(append([]Middleware{}, a.mw...), mw...))
+
 	handler = wrapMiddleware(mw, handler)
 	handler = wrapMiddleware(a.mw, handler)
 
+	a.bind(method, path, handler)
+}
+
+// handleOptions binds the handler for OPTIONS requests to the path without
+// any middleware. A path shared by several routes is only bound once.
+func (a *App) handleOptions(path string, handler Handler) {
+	a.mu.Lock()
+	bound := a.options[path]
+	a.options[path] = true
+	a.mu.Unlock()
+
+	if bound {
+		return
+	}
+
+	a.addRoute(http.MethodOptions, path, nil)
+	a.bind(http.MethodOptions, path, handler)
+}
+
+// bind binds the handler to the mux, setting up the request values and the
+// span for each request.
+func (a *App) bind(method string, path string, handler Handler) {
 	h := func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
@@ -94,11 +173,63 @@ func (a *App) Handle(method string, group string, path string, handler Handler,
 		}
 	}
 
-	finalPath := path
-	if group != "" {
-		finalPath = "/" + group + path
+	a.mux.Handle(method, path, h)
+}
+
+// addRoute records the route along with the names of the middleware that
+// will execute for it, in execution order.
+func (a *App) addRoute(method string, path string, mw []Middleware) {
+	var names []string
+	for _, m := range mw {
+		if m != nil {
+			names = append(names, middlewareName(m))
+		}
 	}
-	a.mux.Handle(method, finalPath, h)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.routes = append(a.routes, Route{
+		Method:     method,
+		Path:       path,
+		Middleware: names,
+	})
+}
+
+// =============================================================================
+
+// Route describes a route registered with the application.
+type Route struct {
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	Middleware []string `json:"middleware"`
+}
+
+// HasMiddleware reports whether the named middleware runs for this route.
+func (r Route) HasMiddleware(name string) bool {
+	for _, mw := range r.Middleware {
+		if mw == name {
+			return true
+		}
+	}
+	return false
+}
+
+// middlewareName returns the package qualified name of the function that
+// constructed the middleware, such as "mid.Authenticate".
+func middlewareName(mw Middleware) string {
+	name := runtime.FuncForPC(reflect.ValueOf(mw).Pointer()).Name()
+
+	// The middleware functions are closures returned by a constructor so
+	// the runtime name looks like "github.com/org/pkg.Constructor.func1".
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	if idx := strings.Index(name, ".func"); idx >= 0 {
+		name = name[:idx]
+	}
+
+	return name
 }
 
 // validateShutdown validates the error for special conditions that do not
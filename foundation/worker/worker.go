@@ -9,6 +9,9 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // JobFunc defines a function that can execute work for a specific job.
@@ -108,8 +111,20 @@ func (w *Worker) Start(ctx context.Context, fn JobFunc) (string, error) {
 		deadline = time.Now().Add(time.Second)
 	}
 
+	// The job runs on its own context so it isn't canceled with the caller,
+	// but it carries the caller's span so the work shows up in the trace that
+	// scheduled it. The job span also links back to the scheduling span since
+	// it runs asynchronously and may outlive it.
+	parent := trace.SpanContextFromContext(ctx)
+	jobCtx := trace.ContextWithSpanContext(context.Background(), parent)
+
+	jobCtx, span := otel.Tracer("foundation.worker").Start(jobCtx, "foundation.worker.job",
+		trace.WithLinks(trace.Link{SpanContext: parent}),
+		trace.WithAttributes(attribute.String("worker.key", workKey)),
+	)
+
 	// Create a cancel function and keep it for stop/shutdown purposes.
-	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	ctx, cancel := context.WithDeadline(jobCtx, deadline)
 
 	// Register this new G as running.
 	w.trackWork(workKey, cancel)
@@ -127,6 +142,7 @@ func (w *Worker) Start(ctx context.Context, fn JobFunc) (string, error) {
 		// to the outer G we are done.
 		defer func() {
 			cancel()
+			span.End()
 			w.removeWork(workKey)
 			w.wg.Done()
 		}()
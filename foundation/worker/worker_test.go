@@ -7,6 +7,10 @@ import (
 	"time"
 
 	"github.com/ardanlabs/service/foundation/worker"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func Test_Worker(t *testing.T) {
@@ -150,3 +154,63 @@ func Test_StopWorker(t *testing.T) {
 		t.Fatalf("Should be able to shutdown work cleanly : %s", err)
 	}
 }
+
+func Test_TraceWorker(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+
+	// The worker starts its spans from the global provider, which is put
+	// back once the test is done so other tests aren't recorded.
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prev)
+	})
+
+	w, err := worker.New(1)
+	if err != nil {
+		t.Fatalf("Should be able to create a worker with max 1 : %s", err)
+	}
+
+	// Schedule the job from within a span, like a request handler would.
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+
+	done := make(chan trace.SpanContext, 1)
+	work := func(ctx context.Context) {
+		done <- trace.SpanContextFromContext(ctx)
+	}
+
+	if _, err := w.Start(ctx, work); err != nil {
+		t.Fatalf("Should be able to execute work : %s", err)
+	}
+	parent.End()
+
+	job := <-done
+	if job.TraceID() != parent.SpanContext().TraceID() {
+		t.Errorf("Exp: %s", parent.SpanContext().TraceID())
+		t.Errorf("Got: %s", job.TraceID())
+		t.Error("Should run the job within the originating trace")
+	}
+
+	if err := w.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Should be able to shutdown work cleanly : %s", err)
+	}
+
+	var found bool
+	for _, s := range sr.Ended() {
+		if s.Name() != "foundation.worker.job" {
+			continue
+		}
+		found = true
+
+		if s.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Error("Should have the scheduling span as the parent of the job span")
+		}
+		if len(s.Links()) != 1 || s.Links()[0].SpanContext.SpanID() != parent.SpanContext().SpanID() {
+			t.Error("Should link the job span to the scheduling span")
+		}
+	}
+
+	if !found {
+		t.Error("Should record a span for the job")
+	}
+}